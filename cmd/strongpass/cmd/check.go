@@ -0,0 +1,106 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/strength"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks a password against a policy",
+	Long: `Checks whether an existing password satisfies a password policy.
+The password is prompted for without echo, or read from the first line of stdin when it is not a terminal.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		password, err := readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+
+		config, err := getGeneratorConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		violations := config.Check(password)
+		for _, violation := range violations {
+			fmt.Println(violation.Message)
+		}
+
+		result := strength.Estimate(password)
+		fmt.Printf("Strength score: %d (%s)\n", result.Score, result.Score)
+		if result.Feedback.Warning != "" {
+			fmt.Println(result.Feedback.Warning)
+		}
+
+		if len(violations) > 0 {
+			return errReported
+		}
+		fmt.Println("The password satisfies the policy")
+		return nil
+	},
+}
+
+// maxPasswordInputLength is the maximum length in bytes of a password that is read from stdin.
+const maxPasswordInputLength = 4096
+
+func init() {
+	addGeneratorConfigFlags(checkCmd)
+	rootCmd.AddCommand(checkCmd)
+}
+
+// readPassword prompts for a password without echo, or reads the first line of stdin when it is not a terminal.
+func readPassword(prompt string) (string, error) {
+	stdin := int(os.Stdin.Fd())
+	if terminal.IsTerminal(stdin) {
+		fmt.Fprint(os.Stderr, prompt)
+		password, err := terminal.ReadPassword(stdin)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to read the password")
+		}
+		return string(password), nil
+	}
+
+	// Reading one byte more than the limit tells a password at the limit apart from a longer one
+	password, err := bufio.NewReader(io.LimitReader(os.Stdin, maxPasswordInputLength+1)).ReadString('\n')
+	if err != nil && (err != io.EOF || password == "") {
+		return "", errors.Wrap(err, "Failed to read the password from stdin")
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if len(password) > maxPasswordInputLength {
+		return "", errors.Errorf("The password from stdin is longer than %d bytes", maxPasswordInputLength)
+	}
+	return password, nil
+}
@@ -0,0 +1,99 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"math/big"
+
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/derive"
+)
+
+var deriveCmd = &cobra.Command{
+	Use:   "derive",
+	Short: "Derives a site-specific password from a master password",
+	Long: `Derives a password from a master password, site, login and counter, so that it can be derived again without storing it.
+The master password is prompted for without echo, or read from the first line of stdin when it is not a terminal.
+The same master password, site, login, counter and policy always derive the same password.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		master, err := readPassword("Master password: ")
+		if err != nil {
+			return err
+		}
+
+		policy, err := getGeneratorConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		password, err := derive.Password([]byte(master), &derive.Config{
+			Site:    deriveSite,
+			Login:   deriveLogin,
+			Counter: deriveCounter,
+			Policy:  policy,
+			Params: &derive.Params{
+				Time:    deriveTime,
+				Memory:  deriveMemory,
+				Threads: deriveThreads,
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		// A derived password has no entropy of its own, since it is a function of the master password,
+		// and the same password is written as many times as the count flag specifies
+		metadata := &outputMetadata{
+			policy:  "derived",
+			charset: describeCharSet(),
+			keyspace: func() *big.Int {
+				return big.NewInt(1)
+			},
+		}
+		return writeValues(metadata, func() (string, string, error) {
+			return password, "", nil
+		})
+	},
+}
+var deriveSite string
+var deriveLogin string
+var deriveCounter uint32
+var deriveTime uint32
+var deriveMemory uint32
+var deriveThreads uint8
+
+func init() {
+	defaultParams := derive.DefaultParams()
+	deriveCmd.Flags().StringVar(&deriveSite, "site", "", "The site of the password, such as example.com")
+	deriveCmd.Flags().StringVar(&deriveLogin, "login", "", "The login of the password, such as a user name or email address")
+	deriveCmd.Flags().Uint32Var(&deriveCounter, "counter", 1, "The counter of the password, which is increased to change the password")
+	deriveCmd.Flags().Uint32Var(&deriveTime, "argon2-time", defaultParams.Time, "The number of Argon2id passes, which changes the derived password")
+	deriveCmd.Flags().Uint32Var(&deriveMemory, "argon2-memory", defaultParams.Memory, "The Argon2id memory in KiB, which changes the derived password")
+	deriveCmd.Flags().Uint8Var(&deriveThreads, "argon2-threads", defaultParams.Threads, "The Argon2id parallelism, which changes the derived password")
+	deriveCmd.MarkFlagRequired("site")
+	addGeneratorConfigFlags(deriveCmd)
+	addOutputFlags(deriveCmd)
+	rootCmd.AddCommand(deriveCmd)
+}
@@ -26,26 +26,82 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"os"
+	"strconv"
+	"strings"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/charset"
+	"github.com/whinarn/strongpass/pkg/crypt"
 	"github.com/whinarn/strongpass/pkg/generator"
+	"github.com/whinarn/strongpass/pkg/verifier"
 )
 
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generates a strong password",
-	Long:  "Generates a strong password with your requirements.",
-	Run: func(cmd *cobra.Command, args []string) {
-		config := getGeneratorConfig()
+	Long: `Generates a strong password with your requirements.
+With --hash, each password is followed by a tab and its hash, in the modular crypt format or the PHC format for argon2id.
+With --verifier, each password is followed by a tab and its server-side verifier for PostgreSQL, MySQL or LDAP.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := getGeneratorConfig(cmd)
+		if err != nil {
+			return err
+		}
+
+		var hashAlgorithm crypt.Algorithm
+		var verifierFormat verifier.Format
+		if generateHash != "" && generateVerifier != "" {
+			return errors.New("A password can only have either a hash or a verifier")
+		} else if generateHash != "" {
+			if hashAlgorithm, err = crypt.ParseAlgorithm(generateHash); err != nil {
+				return err
+			}
+		} else if generateVerifier != "" {
+			if verifierFormat, err = verifier.ParseFormat(generateVerifier); err != nil {
+				return err
+			}
+		} else if generateHashOnly {
+			return errors.New("The hash-only flag requires a hash algorithm or verifier format")
+		}
+		config.Pronounceable = generatePronounceable
+
 		generator, err := generator.New(config)
 		if err != nil {
-			log.Fatal(err)
-			return
+			return err
+		}
+
+		if generatePronounceable {
+			// Pronounceable passwords are far weaker than random ones of the same length, which should not be hidden
+			fmt.Fprintf(os.Stderr, "The pronounceable password has an entropy of %.1f bits\n", generator.Entropy())
 		}
 
-		password := generator.GeneratePassword()
-		fmt.Println(password)
+		metadata := &outputMetadata{
+			policy:   "random",
+			charset:  describeCharSet(),
+			entropy:  generator.Entropy(),
+			keyspace: generator.Keyspace,
+			hashOnly: generateHashOnly,
+		}
+		if generatePronounceable {
+			metadata.policy = "pronounceable"
+		}
+		return writeValues(metadata, func() (string, string, error) {
+			password, err := generator.GeneratePasswordE()
+			if err != nil {
+				return "", "", err
+			}
+
+			var hash string
+			switch {
+			case hashAlgorithm != "":
+				hash, err = hashPassword(password, hashAlgorithm)
+			case verifierFormat != "":
+				hash, err = verifier.Generate(password, verifierFormat)
+			}
+			return password, hash, err
+		})
 	},
 }
 var generateCharSet string
@@ -53,6 +109,7 @@ var generateLowerCaseLetters bool
 var generateUpperCaseLetters bool
 var generateDigits bool
 var generateSpecials bool
+var generateNoAmbiguous bool
 var generateLength int
 var generateMinLength int
 var generateMaxLength int
@@ -60,44 +117,222 @@ var generateMinLowerCaseLetters int
 var generateMinUpperCaseLetters int
 var generateMinDigits int
 var generateMinSpecials int
+var generateMaxLowerCaseLetters int
+var generateMaxUpperCaseLetters int
+var generateMaxDigits int
+var generateMaxSpecials int
+var generateClasses []string
 var generateMinShuffleCount int
 var generateMaxShuffleCount int
+var generatePronounceable bool
+var generateHash string
+var generateHashOnly bool
+var generateVerifier string
 
 func init() {
-	generateCmd.Flags().StringVarP(&generateCharSet, "charset", "c", "", "The custom charset to use")
-	generateCmd.Flags().BoolVarP(&generateLowerCaseLetters, "lowercase", "l", true, "The generator will used lower-case letters")
-	generateCmd.Flags().BoolVarP(&generateUpperCaseLetters, "uppercase", "u", true, "The generator will used upper-case letters")
-	generateCmd.Flags().BoolVarP(&generateDigits, "digits", "d", true, "The generator will use digits")
-	generateCmd.Flags().BoolVarP(&generateSpecials, "specials", "s", true, "The generator will use special symbols")
-	generateCmd.Flags().IntVar(&generateLength, "len", 0, "The length of the password, overrides minimum and maximum")
-	generateCmd.Flags().IntVar(&generateMinLength, "min", 20, "The minimum length of the password")
-	generateCmd.Flags().IntVar(&generateMaxLength, "max", 26, "The maximum length of the password")
-	generateCmd.Flags().IntVar(&generateMinLowerCaseLetters, "minlowercase", 1, "The minumum number of lower-case letters in the password")
-	generateCmd.Flags().IntVar(&generateMinUpperCaseLetters, "minuppercase", 1, "The minumum number of upper-case letters in the password")
-	generateCmd.Flags().IntVar(&generateMinDigits, "mindigits", 1, "The minumum number of digits in the password")
-	generateCmd.Flags().IntVar(&generateMinSpecials, "minspecials", 1, "The minumum number of special symbols in the password")
+	addGeneratorConfigFlags(generateCmd)
+	addOutputFlags(generateCmd)
 	generateCmd.Flags().IntVar(&generateMinShuffleCount, "minshuffle", 4, "The minumum number of random shuffles")
 	generateCmd.Flags().IntVar(&generateMaxShuffleCount, "maxshuffle", 10, "The maximum number of random shuffles")
+	generateCmd.Flags().MarkDeprecated("minshuffle", "passwords are now drawn uniformly without shuffles")
+	generateCmd.Flags().MarkDeprecated("maxshuffle", "passwords are now drawn uniformly without shuffles")
+	generateCmd.Flags().BoolVar(&generatePronounceable, "pronounceable", false,
+		"The generator will create a pronounceable password that is easy to read out loud, at the cost of a lower entropy")
+	generateCmd.Flags().StringVar(&generateHash, "hash", "", "The hash algorithm of the password, either sha512-crypt, bcrypt, argon2id or apr1")
+	generateCmd.Flags().StringVar(&generateVerifier, "verifier", "",
+		"The server-side verifier of the password, either scram-sha-256 for PostgreSQL, mysql-caching-sha2 for MySQL or ssha for LDAP")
+	generateCmd.Flags().BoolVar(&generateHashOnly, "hash-only", false, "Only the hash or verifier of the password is printed, without its length, such as for /etc/shadow")
 	rootCmd.AddCommand(generateCmd)
 }
 
-func getGeneratorConfig() *generator.Config {
+// addGeneratorConfigFlags adds the flags of the password policy, which are shared by the commands that use one.
+func addGeneratorConfigFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&generateCharSet, "charset", "c", "",
+		"The custom charset to use, as an expression with ranges like a-z, POSIX classes like [:punct:], "+
+			"Unicode categories like \\p{Lu} and subtraction like a-z--aeiou. Escape literal characters with \\")
+	cmd.Flags().BoolVarP(&generateLowerCaseLetters, "lowercase", "l", true, "The generator will used lower-case letters")
+	cmd.Flags().BoolVarP(&generateUpperCaseLetters, "uppercase", "u", true, "The generator will used upper-case letters")
+	cmd.Flags().BoolVarP(&generateDigits, "digits", "d", true, "The generator will use digits")
+	cmd.Flags().BoolVarP(&generateSpecials, "specials", "s", true, "The generator will use special symbols")
+	cmd.Flags().BoolVar(&generateNoAmbiguous, "no-ambiguous", false, "The generator will not use visually ambiguous characters, such as 0 and O")
+	cmd.Flags().IntVar(&generateLength, "len", 0, "The length of the password, overrides minimum and maximum")
+	cmd.Flags().IntVar(&generateMinLength, "min", 20, "The minimum length of the password")
+	cmd.Flags().IntVar(&generateMaxLength, "max", 26, "The maximum length of the password")
+	cmd.Flags().IntVar(&generateMinLowerCaseLetters, "minlowercase", 1, "The minumum number of lower-case letters in the password")
+	cmd.Flags().IntVar(&generateMinUpperCaseLetters, "minuppercase", 1, "The minumum number of upper-case letters in the password")
+	cmd.Flags().IntVar(&generateMinDigits, "mindigits", 1, "The minumum number of digits in the password")
+	cmd.Flags().IntVar(&generateMinSpecials, "minspecials", 1, "The minumum number of special symbols in the password")
+	cmd.Flags().IntVar(&generateMaxLowerCaseLetters, "maxlowercase", 0, "The maximum number of lower-case letters in the password, 0 means no maximum")
+	cmd.Flags().IntVar(&generateMaxUpperCaseLetters, "maxuppercase", 0, "The maximum number of upper-case letters in the password, 0 means no maximum")
+	cmd.Flags().IntVar(&generateMaxDigits, "maxdigits", 0, "The maximum number of digits in the password, 0 means no maximum")
+	cmd.Flags().IntVar(&generateMaxSpecials, "maxspecials", 0, "The maximum number of special symbols in the password, 0 means no maximum")
+	cmd.Flags().StringArrayVar(&generateClasses, "class", nil,
+		"A character class as name:chars:min:max, where chars is a charset expression, overrides the other character options. "+
+			"The chars of the built-in classes lowercase, uppercase, digits and specials can be left out. A max of 0 means no maximum")
+}
+
+func getGeneratorConfig(cmd *cobra.Command) (*generator.Config, error) {
 	if generateLength > 0 {
 		generateMinLength = generateLength
 		generateMaxLength = generateLength
 	}
 
-	return &generator.Config{
-		CharSet:               []rune(generateCharSet),
+	var charSet []rune
+	if generateCharSet != "" {
+		var err error
+		charSet, err = charset.Parse(generateCharSet)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var classes []generator.CharClass
+	for _, value := range generateClasses {
+		class, err := parseCharClass(value)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
+	}
+
+	config := &generator.Config{
+		CharSet:               charSet,
+		Classes:               classes,
 		AllowLowerCaseLetters: generateLowerCaseLetters,
 		AllowUpperCaseLetters: generateUpperCaseLetters,
 		AllowDigits:           generateDigits,
 		AllowSpecials:         generateSpecials,
+		ExcludeAmbiguous:      generateNoAmbiguous,
 		MinLength:             generateMinLength,
 		MaxLength:             generateMaxLength,
 		MinLowerCaseLetters:   generateMinLowerCaseLetters,
 		MinUpperCaseLetters:   generateMinUpperCaseLetters,
 		MinDigits:             generateMinDigits,
 		MinSpecials:           generateMinSpecials,
+		MaxLowerCaseLetters:   generateMaxLowerCaseLetters,
+		MaxUpperCaseLetters:   generateMaxUpperCaseLetters,
+		MaxDigits:             generateMaxDigits,
+		MaxSpecials:           generateMaxSpecials,
+	}
+
+	// Minimums that are not set explicitly are dropped for classes that are not available
+	defaultMinimums := []struct {
+		flag    string
+		min     *int
+		allowed bool
+		class   generator.CharClass
+	}{
+		{"minlowercase", &config.MinLowerCaseLetters, generateLowerCaseLetters, generator.LowerCaseLetters(0, 0)},
+		{"minuppercase", &config.MinUpperCaseLetters, generateUpperCaseLetters, generator.UpperCaseLetters(0, 0)},
+		{"mindigits", &config.MinDigits, generateDigits, generator.Digits(0, 0)},
+		{"minspecials", &config.MinSpecials, generateSpecials, generator.Specials(0, 0)},
+	}
+	for _, minimum := range defaultMinimums {
+		if !cmd.Flags().Changed(minimum.flag) && !isClassAvailable(minimum.class, minimum.allowed, charSet) {
+			*minimum.min = 0
+		}
+	}
+
+	return config, nil
+}
+
+// describeCharSet describes the characters of the passwords for the structured output.
+func describeCharSet() string {
+	if generatePronounceable {
+		return "pronounceable syllables"
+	}
+
+	var description string
+	if len(generateClasses) > 0 {
+		names := make([]string, len(generateClasses))
+		for i, value := range generateClasses {
+			names[i] = strings.SplitN(value, ":", 2)[0]
+		}
+		description = strings.Join(names, ", ")
+	} else if generateCharSet != "" {
+		description = generateCharSet
+	} else {
+		var names []string
+		builtIns := []struct {
+			name    string
+			allowed bool
+		}{
+			{generator.LowerCaseLettersClassName, generateLowerCaseLetters},
+			{generator.UpperCaseLettersClassName, generateUpperCaseLetters},
+			{generator.DigitsClassName, generateDigits},
+			{generator.SpecialsClassName, generateSpecials},
+		}
+		for _, builtIn := range builtIns {
+			if builtIn.allowed {
+				names = append(names, builtIn.name)
+			}
+		}
+		description = strings.Join(names, ", ")
+	}
+
+	if generateNoAmbiguous {
+		description += " without ambiguous characters"
+	}
+	return description
+}
+
+func isClassAvailable(class generator.CharClass, allowed bool, charSet []rune) bool {
+	if len(charSet) == 0 {
+		return allowed
+	}
+
+	for _, r := range class.Runes {
+		for _, c := range charSet {
+			if r == c {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseCharClass parses a character class in the format name:chars:min:max, where chars is a charset expression.
+// The chars may contain colons, since the name ends at the first colon and the bounds are the last two fields.
+func parseCharClass(value string) (generator.CharClass, error) {
+	nameEnd := strings.Index(value, ":")
+	maxStart := strings.LastIndex(value, ":")
+	minStart := -1
+	if maxStart > 0 {
+		minStart = strings.LastIndex(value[:maxStart], ":")
+	}
+	if nameEnd < 0 || minStart <= nameEnd {
+		return generator.CharClass{}, errors.Errorf("The character class %q is not in the format name:chars:min:max", value)
+	}
+
+	name := value[:nameEnd]
+	min, err := strconv.Atoi(value[minStart+1 : maxStart])
+	if err != nil {
+		return generator.CharClass{}, errors.Errorf("The minimum of character class %s is not a number", name)
+	}
+	max, err := strconv.Atoi(value[maxStart+1:])
+	if err != nil {
+		return generator.CharClass{}, errors.Errorf("The maximum of character class %s is not a number", name)
+	}
+
+	chars := value[nameEnd+1 : minStart]
+	if chars != "" {
+		runes, err := charset.Parse(chars)
+		if err != nil {
+			return generator.CharClass{}, errors.Wrapf(err, "Failed to parse the characters of character class %s", name)
+		}
+		return generator.CharClass{Name: name, Runes: runes, Min: min, Max: max}, nil
+	}
+
+	switch name {
+	case generator.LowerCaseLettersClassName:
+		return generator.LowerCaseLetters(min, max), nil
+	case generator.UpperCaseLettersClassName:
+		return generator.UpperCaseLetters(min, max), nil
+	case generator.DigitsClassName:
+		return generator.Digits(min, max), nil
+	case generator.SpecialsClassName:
+		return generator.Specials(min, max), nil
+	default:
+		return generator.CharClass{}, errors.Errorf("The character class %s has no characters", name)
 	}
 }
@@ -0,0 +1,97 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/encoder"
+)
+
+var generateBytesCmd = &cobra.Command{
+	Use:   "generate-bytes",
+	Short: "Generates random bytes as text",
+	Long: `Generates random bytes and encodes them as text, such as for keys and raw secrets.
+The length is the number of random bytes, or the number of characters of the output with --chars,
+in which case every character is drawn from the alphabet of the encoding and the output does not always decode to whole bytes.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		encoding, err := encoder.Lookup(generateBytesEncoding)
+		if err != nil {
+			return err
+		}
+		return writeEncodedBytes(encoding, generateBytesLength, generateBytesChars)
+	},
+}
+var generateBytesEncoding string
+var generateBytesLength int
+var generateBytesChars int
+
+func init() {
+	generateBytesCmd.Flags().StringVarP(&generateBytesEncoding, "encoding", "e", "hex",
+		"The encoding of the bytes, one of "+strings.Join(encoder.Names(), ", ")+". The -raw encodings have no padding")
+	generateBytesCmd.Flags().IntVarP(&generateBytesLength, "len", "l", 32, "The number of random bytes")
+	generateBytesCmd.Flags().IntVar(&generateBytesChars, "chars", 0, "The number of characters of the output, overrides the number of bytes")
+	addOutputFlags(generateBytesCmd)
+	rootCmd.AddCommand(generateBytesCmd)
+}
+
+// writeEncodedBytes writes random bytes in the encoding, with a length in characters if chars is over zero and in bytes otherwise.
+func writeEncodedBytes(encoding *encoder.Encoding, length, chars int) error {
+	// The lengths are validated before the keyspace of unique values is calculated from them
+	if chars < 0 {
+		return errors.New("The number of characters must be over zero")
+	} else if chars == 0 && length <= 0 {
+		return errors.New("The number of bytes must be over zero")
+	}
+
+	metadata := &outputMetadata{
+		policy:  "bytes",
+		charset: encoding.Name,
+	}
+	if chars > 0 {
+		metadata.entropy = float64(chars) * math.Log2(float64(len(encoding.Alphabet)))
+		metadata.keyspace = func() *big.Int {
+			return new(big.Int).Exp(big.NewInt(int64(len(encoding.Alphabet))), big.NewInt(int64(chars)), nil)
+		}
+		return writeValues(metadata, func() (string, string, error) {
+			text, err := encoding.GenerateChars(chars)
+			return text, "", err
+		})
+	}
+
+	metadata.entropy = float64(8 * length)
+	metadata.keyspace = func() *big.Int {
+		return new(big.Int).Lsh(big.NewInt(1), uint(8*length))
+	}
+	return writeValues(metadata, func() (string, string, error) {
+		text, err := encoding.GenerateBytes(length)
+		return text, "", err
+	})
+}
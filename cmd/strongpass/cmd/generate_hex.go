@@ -25,39 +25,24 @@ SOFTWARE.
 package cmd
 
 import (
-	"crypto/rand"
-	"encoding/hex"
-	"fmt"
-	"log"
-	"strings"
-
-	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/encoder"
 )
 
 var generateHexCmd = &cobra.Command{
 	Use:   "generate-hex",
-	Short: "Generates a strong password",
-	Long:  "Generates a strong password with your requirements.",
-	Run: func(cmd *cobra.Command, args []string) {
-		if generateHexLength <= 0 {
-			log.Fatal("The length must be over zero.")
-			return
+	Short: "Generates random bytes as hexadecimals",
+	Long:  "Generates random bytes as hexadecimals, the same as generate-bytes --encoding hex, or HEX with --uppercase.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := "hex"
+		if generateHexUpper {
+			name = "HEX"
 		}
-
-		buffer := make([]byte, generateHexLength)
-		_, err := rand.Read(buffer)
+		encoding, err := encoder.Lookup(name)
 		if err != nil {
-			log.Fatal(errors.Wrap(err, "Failed to generate random bytes"))
-			return
+			return err
 		}
-
-		password := hex.EncodeToString(buffer)
-		if generateHexUpper {
-			password = strings.ToUpper(password)
-		}
-
-		fmt.Println(password)
+		return writeEncodedBytes(encoding, generateHexLength, 0)
 	},
 }
 var generateHexUpper bool
@@ -66,5 +51,6 @@ var generateHexLength int
 func init() {
 	generateHexCmd.Flags().BoolVarP(&generateHexUpper, "uppercase", "u", false, "The generator will use upper-cased hexadecimals")
 	generateHexCmd.Flags().IntVarP(&generateHexLength, "len", "l", 32, "The length of the password in bytes")
+	addOutputFlags(generateHexCmd)
 	rootCmd.AddCommand(generateHexCmd)
 }
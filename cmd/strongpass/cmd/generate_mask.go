@@ -0,0 +1,77 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/generator"
+)
+
+var generateMaskCmd = &cobra.Command{
+	Use:   "generate-mask <mask>",
+	Short: "Generates a password from a mask",
+	Long: `Generates a password from a hashcat-style mask, such as ?u?l?l?l-?d?d?d?d-?s.
+The placeholders are ?l (lower-case letters), ?u (upper-case letters), ?d (digits), ?s (special symbols),
+?a (all of them), ?1 to ?4 (the custom charsets) and ?? (a literal question mark). Any other character is literal.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mask, err := generator.ParseMask(args[0], generateMaskCustomCharSets[:]...)
+		if err != nil {
+			return err
+		}
+
+		if generateMaskKeyspace {
+			fmt.Println(mask.Keyspace())
+			return nil
+		}
+
+		fmt.Fprintf(os.Stderr, "The mask has a keyspace of %s (%.1f bits)\n", mask.Keyspace(), mask.Entropy())
+		metadata := &outputMetadata{
+			policy:   "mask",
+			charset:  args[0],
+			entropy:  mask.Entropy(),
+			keyspace: mask.Keyspace,
+		}
+		return writeValues(metadata, func() (string, string, error) {
+			password, err := mask.GeneratePasswordE()
+			return password, "", err
+		})
+	},
+}
+var generateMaskCustomCharSets [generator.MaxCustomCharSets]string
+var generateMaskKeyspace bool
+
+func init() {
+	for i := range generateMaskCustomCharSets {
+		generateMaskCmd.Flags().StringVarP(&generateMaskCustomCharSets[i], fmt.Sprintf("custom-charset%d", i+1), fmt.Sprint(i+1), "",
+			fmt.Sprintf("The custom charset of the ?%d placeholder, such as ?l?d or abc", i+1))
+	}
+	generateMaskCmd.Flags().BoolVar(&generateMaskKeyspace, "keyspace", false, "Prints the keyspace of the mask instead of a password")
+	addOutputFlags(generateMaskCmd)
+	rootCmd.AddCommand(generateMaskCmd)
+}
@@ -0,0 +1,84 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/passphrase"
+)
+
+var generatePhraseCmd = &cobra.Command{
+	Use:   "generate-phrase",
+	Short: "Generates a strong passphrase",
+	Long:  "Generates a strong diceware-style passphrase from a wordlist.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		wordlist, err := passphrase.ParseWordlist(generatePhraseWordlist)
+		if err != nil {
+			return err
+		}
+
+		generator, err := passphrase.New(&passphrase.Config{
+			Wordlist:      wordlist,
+			WordCount:     generatePhraseWords,
+			Separator:     generatePhraseSeparator,
+			Capitalize:    generatePhraseCapitalize,
+			InsertDigit:   generatePhraseDigit,
+			InsertSpecial: generatePhraseSpecial,
+		})
+		if err != nil {
+			return err
+		}
+
+		metadata := &outputMetadata{
+			policy:   "passphrase",
+			charset:  "eff-" + strings.ToLower(generatePhraseWordlist),
+			entropy:  generator.Entropy(),
+			keyspace: generator.Keyspace,
+		}
+		return writeValues(metadata, func() (string, string, error) {
+			phrase, err := generator.GeneratePassphraseE()
+			return phrase, "", err
+		})
+	},
+}
+var generatePhraseWordlist string
+var generatePhraseWords int
+var generatePhraseSeparator string
+var generatePhraseCapitalize bool
+var generatePhraseDigit bool
+var generatePhraseSpecial bool
+
+func init() {
+	generatePhraseCmd.Flags().StringVar(&generatePhraseWordlist, "wordlist", "large", "The wordlist to use, either large or short")
+	generatePhraseCmd.Flags().IntVarP(&generatePhraseWords, "words", "w", 6, "The number of words in the passphrase")
+	generatePhraseCmd.Flags().StringVar(&generatePhraseSeparator, "separator", "-", "The separator between words, which cannot be empty")
+	generatePhraseCmd.Flags().BoolVarP(&generatePhraseCapitalize, "capitalize", "c", false, "The generator will randomly capitalize words")
+	generatePhraseCmd.Flags().BoolVarP(&generatePhraseDigit, "digit", "d", false, "The generator will insert a random digit")
+	generatePhraseCmd.Flags().BoolVarP(&generatePhraseSpecial, "special", "s", false, "The generator will insert a random special symbol")
+	addOutputFlags(generatePhraseCmd)
+	rootCmd.AddCommand(generatePhraseCmd)
+}
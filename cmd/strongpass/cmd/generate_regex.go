@@ -0,0 +1,76 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/charset"
+	"github.com/whinarn/strongpass/pkg/regexgen"
+)
+
+var generateRegexCmd = &cobra.Command{
+	Use:   "generate-regex <pattern>",
+	Short: "Generates a string that matches a regular expression",
+	Long:  "Generates a random string that matches a regular expression, such as ^[A-Za-z0-9]{32}$, in the Go regular expression syntax.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config := regexgen.DefaultConfig()
+		config.MaxRepeat = generateRegexMaxRepeat
+		if generateRegexAlphabet != "" {
+			alphabet, err := charset.Parse(generateRegexAlphabet)
+			if err != nil {
+				return err
+			}
+			config.Alphabet = alphabet
+		}
+
+		generator, err := regexgen.New(args[0], config)
+		if err != nil {
+			return err
+		}
+
+		// The keyspace counts the ways that the pattern can match, which is more than the distinct strings
+		// if a string matches in more than one way, so it does not bound the unique values
+		metadata := &outputMetadata{
+			policy:  "regex",
+			charset: args[0],
+			entropy: generator.Entropy(),
+		}
+		return writeValues(metadata, func() (string, string, error) {
+			value, err := generator.GenerateE()
+			return value, "", err
+		})
+	},
+}
+var generateRegexMaxRepeat int
+var generateRegexAlphabet string
+
+func init() {
+	generateRegexCmd.Flags().IntVar(&generateRegexMaxRepeat, "maxrepeat", 16, "The maximum number of repeats of the unbounded quantifiers *, + and {n,}")
+	generateRegexCmd.Flags().StringVar(&generateRegexAlphabet, "alphabet", "",
+		"The charset expression of the characters that . and character classes are drawn from, by default printable ASCII")
+	addOutputFlags(generateRegexCmd)
+	rootCmd.AddCommand(generateRegexCmd)
+}
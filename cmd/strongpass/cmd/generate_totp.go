@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/otp"
+)
+
+var generateTOTPCmd = &cobra.Command{
+	Use:   "generate-totp",
+	Short: "Generates a TOTP secret",
+	Long:  "Generates a random TOTP secret and prints its otpauth:// URI, which authenticator apps can import.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		algorithm, err := otp.ParseAlgorithm(generateTOTPAlgorithm)
+		if err != nil {
+			return err
+		}
+		newKey := func() (*otp.Key, error) {
+			key, err := otp.NewKey(generateTOTPIssuer, generateTOTPAccount, generateTOTPSize)
+			if err != nil {
+				return nil, err
+			}
+			key.Digits = generateTOTPDigits
+			key.Period = generateTOTPPeriod
+			key.Algorithm = algorithm
+			// The parameters are validated by generating a code
+			_, err = key.Code(time.Now())
+			return key, err
+		}
+		// The configuration is validated before any output is written
+		if _, err := newKey(); err != nil {
+			return err
+		}
+
+		metadata := &outputMetadata{
+			policy:  "totp",
+			charset: "base32",
+			entropy: float64(8 * generateTOTPSize),
+			keyspace: func() *big.Int {
+				return new(big.Int).Lsh(big.NewInt(1), uint(8*generateTOTPSize))
+			},
+		}
+		return writeValues(metadata, func() (string, string, error) {
+			key, err := newKey()
+			if err != nil {
+				return "", "", err
+			}
+			return key.URI(), "", nil
+		})
+	},
+}
+var generateTOTPIssuer string
+var generateTOTPAccount string
+var generateTOTPSize int
+var generateTOTPDigits int
+var generateTOTPPeriod int
+var generateTOTPAlgorithm string
+
+func init() {
+	generateTOTPCmd.Flags().StringVar(&generateTOTPIssuer, "issuer", "", "The issuer of the secret, such as the company or service name")
+	generateTOTPCmd.Flags().StringVar(&generateTOTPAccount, "account", "", "The account of the secret, such as a user name or email address")
+	generateTOTPCmd.Flags().IntVar(&generateTOTPSize, "size", otp.DefaultSecretSize, "The size of the secret in bytes")
+	generateTOTPCmd.Flags().IntVar(&generateTOTPDigits, "digits", 6, "The number of digits of the codes")
+	generateTOTPCmd.Flags().IntVar(&generateTOTPPeriod, "period", 30, "The period of the codes in seconds")
+	generateTOTPCmd.Flags().StringVar(&generateTOTPAlgorithm, "algorithm", string(otp.SHA1), "The algorithm of the codes, either SHA1, SHA256 or SHA512")
+	generateTOTPCmd.MarkFlagRequired("account")
+	addOutputFlags(generateTOTPCmd)
+	rootCmd.AddCommand(generateTOTPCmd)
+}
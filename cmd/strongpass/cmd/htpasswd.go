@@ -0,0 +1,168 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/crypt"
+	"github.com/whinarn/strongpass/pkg/generator"
+)
+
+var htpasswdCmd = &cobra.Command{
+	Use:   "htpasswd <file> <user>",
+	Short: "Adds or updates a user in an htpasswd file",
+	Long: `Generates a password for a user and adds or updates the user in an htpasswd file, which is created if it does not exist.
+The password is printed, and only its hash is written to the file. Note that nginx on glibc systems does not support bcrypt, but apr1 and sha512-crypt.`,
+	Args: cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, user := args[0], args[1]
+		if user == "" || strings.ContainsAny(user, ":\r\n") {
+			return errors.New("The user cannot be empty or contain colons or line breaks")
+		}
+		algorithm, err := crypt.ParseAlgorithm(htpasswdHash)
+		if err != nil {
+			return err
+		}
+		if algorithm == crypt.Argon2id {
+			return errors.New("Web servers do not support argon2id in htpasswd files")
+		}
+
+		config, err := getGeneratorConfig(cmd)
+		if err != nil {
+			return err
+		}
+		generator, err := generator.New(config)
+		if err != nil {
+			return err
+		}
+		password, err := generator.GeneratePasswordE()
+		if err != nil {
+			return err
+		}
+		hash, err := hashPassword(password, algorithm)
+		if err != nil {
+			return err
+		}
+
+		updated, err := updateHtpasswdFile(file, user, hash)
+		if err != nil {
+			return err
+		}
+		if updated {
+			fmt.Fprintf(os.Stderr, "Updated the password of %s in %s\n", user, file)
+		} else {
+			fmt.Fprintf(os.Stderr, "Added %s to %s\n", user, file)
+		}
+		fmt.Println(password)
+		return nil
+	},
+}
+var htpasswdHash string
+
+func init() {
+	htpasswdCmd.Flags().StringVar(&htpasswdHash, "hash", string(crypt.Bcrypt), "The hash algorithm, either bcrypt, sha512-crypt or apr1")
+	addGeneratorConfigFlags(htpasswdCmd)
+	rootCmd.AddCommand(htpasswdCmd)
+}
+
+// updateHtpasswdFile sets the hash of a user in an htpasswd file, and returns true if the user was already in it. The file is
+// replaced atomically with the same permissions, owner and group, or created with permissions for its owner only.
+func updateHtpasswdFile(file, user, hash string) (bool, error) {
+	// A symbolic link is followed, so that its target is replaced rather than the link itself
+	if resolved, err := filepath.EvalSymlinks(file); err == nil {
+		file = resolved
+	} else if !os.IsNotExist(err) {
+		return false, errors.Wrap(err, "Failed to read the htpasswd file")
+	}
+
+	mode := os.FileMode(0600)
+	var info os.FileInfo
+	content, err := ioutil.ReadFile(file)
+	if err == nil {
+		if info, err = os.Stat(file); err != nil {
+			return false, errors.Wrap(err, "Failed to read the htpasswd file")
+		}
+		mode = info.Mode().Perm()
+	} else if !os.IsNotExist(err) {
+		return false, errors.Wrap(err, "Failed to read the htpasswd file")
+	}
+
+	entry := user + ":" + hash
+	updated := false
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	if len(content) == 0 {
+		lines = nil
+	}
+	for i, line := range lines {
+		if strings.HasPrefix(line, user+":") {
+			lines[i] = entry
+			updated = true
+		}
+	}
+	if !updated {
+		lines = append(lines, entry)
+	}
+
+	temp, err := ioutil.TempFile(filepath.Dir(file), "."+filepath.Base(file))
+	if err != nil {
+		return false, errors.Wrap(err, "Failed to write the htpasswd file")
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.WriteString(strings.Join(lines, "\n") + "\n")
+	if err == nil {
+		// The content is on disk before the rename, so that a crash cannot leave an empty file in place of the old one
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temp.Name(), mode)
+	}
+	if err == nil && info != nil {
+		// The web server often only reads the file through its group, such as www-data
+		err = chownLike(temp.Name(), info)
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), file)
+	}
+	return updated, errors.Wrap(err, "Failed to write the htpasswd file")
+}
+
+// hashPassword hashes a password, with a warning if bcrypt ignores the end of it.
+func hashPassword(password string, algorithm crypt.Algorithm) (string, error) {
+	if algorithm == crypt.Bcrypt && len(password) > crypt.BcryptMaxPasswordLength {
+		fmt.Fprintf(os.Stderr, "Warning: The password is %d bytes long, but bcrypt only uses the first %d bytes of it\n",
+			len(password), crypt.BcryptMaxPasswordLength)
+	}
+	return crypt.Hash(password, algorithm)
+}
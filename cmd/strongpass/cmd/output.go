@@ -0,0 +1,325 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// maxDuplicatesPerValue bounds the duplicates that are drawn in a row for unique values, per value of the count. When
+// the last distinct value has a chance of 1 in count of being drawn, all of them being duplicates has a chance of about e^-64.
+const maxDuplicatesPerValue = 64
+
+const (
+	textOutput   = "text"
+	jsonOutput   = "json"
+	ndjsonOutput = "ndjson"
+	csvOutput    = "csv"
+	envOutput    = "env"
+	yamlOutput   = "yaml"
+)
+
+var outputCount int
+var outputUnique bool
+var outputSeparator = "\n"
+var outputNull bool
+var outputFormat string
+
+// outputMetadata describes the values that a command generates.
+type outputMetadata struct {
+	// policy is the name of how the values are generated, such as random or token
+	policy string
+	// charset describes the characters of the values
+	charset string
+	// entropy is the entropy of each value in bits
+	entropy float64
+	// keyspace returns the number of distinct values, which is only used for unique values. It is nil if the number
+	// is not known, such as for patterns that can match a string in more than one way
+	keyspace func() *big.Int
+	// hashOnly leaves out the secrets of the values that have a hash
+	hashOnly bool
+}
+
+// outputRecord is a generated value with its metadata, as it is written in the structured formats.
+type outputRecord struct {
+	Secret      string  `json:"secret,omitempty"`
+	Hash        string  `json:"hash,omitempty"`
+	Length      int     `json:"length,omitempty"`
+	EntropyBits float64 `json:"entropy_bits"`
+	Charset     string  `json:"charset"`
+	Policy      string  `json:"policy"`
+	Timestamp   string  `json:"timestamp"`
+}
+
+// addOutputFlags adds the flags of how many values are written and how, which are shared by the commands that generate values.
+// A command that already has a separator flag, such as the word separator of generate-phrase, keeps it, and its text output
+// is separated by newlines or null characters.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&outputCount, "count", 1, "The number of values to generate")
+	cmd.Flags().BoolVar(&outputUnique, "unique", false, "The generated values will have no duplicates")
+	if cmd.Flags().Lookup("separator") == nil {
+		cmd.Flags().StringVar(&outputSeparator, "separator", "\n", "The separator written after each value of the text output")
+	}
+	cmd.Flags().BoolVarP(&outputNull, "null", "0", false, "The values of the text output will be separated by a null character, overrides the separator")
+	cmd.Flags().StringVarP(&outputFormat, "output", "o", textOutput,
+		"The output format, either text, json, ndjson, csv, env or yaml. The structured formats include the length, entropy, "+
+			"charset, policy and time of generation of each value. The json output is an object of strings for one value, as "+
+			"Terraform external data sources require, and an array of them for more values")
+}
+
+// writeValues writes the values returned by next to stdout, as many as the count flag specifies. A value is a secret and an
+// optional hash of it, and the metadata describes every value. The output is only written once every value is generated,
+// so an error leaves no partial output, such as an unterminated JSON array.
+func writeValues(metadata *outputMetadata, next func() (secret, hash string, err error)) error {
+	if outputCount <= 0 {
+		return errors.New("The count must be over zero")
+	}
+	switch outputFormat {
+	case textOutput, jsonOutput, ndjsonOutput, csvOutput, envOutput, yamlOutput:
+	default:
+		return errors.Errorf("Unknown output format %q, expected text, json, ndjson, csv, env or yaml", outputFormat)
+	}
+
+	var seen map[string]bool
+	if outputUnique {
+		if metadata.keyspace != nil && metadata.keyspace().Cmp(big.NewInt(int64(outputCount))) < 0 {
+			return errors.Errorf("There are fewer than %d distinct values with the configuration", outputCount)
+		}
+		seen = make(map[string]bool, outputCount)
+	}
+
+	var writer bytes.Buffer
+	var csvWriter *csv.Writer
+	switch outputFormat {
+	case jsonOutput:
+		if outputCount > 1 {
+			writer.WriteString("[")
+		}
+	case csvOutput:
+		csvWriter = csv.NewWriter(&writer)
+		csvWriter.Write(outputFieldNames)
+	}
+
+	duplicates := 0
+	for i := 0; i < outputCount; i++ {
+		secret, hash, err := next()
+		if err != nil {
+			return err
+		}
+		if seen != nil {
+			if seen[secret] {
+				// Duplicates are drawn again, which keeps the values uniform among the distinct ones
+				duplicates++
+				if duplicates > maxDuplicatesPerValue*outputCount {
+					return errors.Errorf("Failed to generate %d distinct values, there are likely fewer with the configuration", outputCount)
+				}
+				i--
+				continue
+			}
+			seen[secret] = true
+			duplicates = 0
+		}
+
+		record := &outputRecord{
+			Secret:      secret,
+			Hash:        hash,
+			Length:      utf8.RuneCountInString(secret),
+			EntropyBits: math.Round(metadata.entropy*100) / 100,
+			Charset:     metadata.charset,
+			Policy:      metadata.policy,
+			Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		}
+		if metadata.hashOnly && hash != "" {
+			// The length of a secret narrows down the guesses of anyone with only its hash
+			record.Secret = ""
+			record.Length = 0
+		}
+		if csvWriter != nil {
+			csvWriter.Write(record.fields())
+			continue
+		}
+		writer.WriteString(formatRecord(record, i))
+	}
+
+	switch outputFormat {
+	case jsonOutput:
+		if outputCount > 1 {
+			writer.WriteString("\n]")
+		}
+		writer.WriteString("\n")
+	case csvOutput:
+		csvWriter.Flush()
+		if err := csvWriter.Error(); err != nil {
+			return errors.Wrap(err, "Failed to write the generated values")
+		}
+	}
+	_, err := writer.WriteTo(os.Stdout)
+	return errors.Wrap(err, "Failed to write the generated values")
+}
+
+// formatRecord formats the record with the specified index in the output format, except for CSV.
+func formatRecord(record *outputRecord, index int) string {
+	switch outputFormat {
+	case jsonOutput:
+		if outputCount == 1 {
+			return formatStringObject(record, "")
+		}
+		value := formatStringObject(record, "  ")
+		if index > 0 {
+			return ",\n  " + value
+		}
+		return "\n  " + value
+	case ndjsonOutput:
+		return marshalJSON(record, "") + "\n"
+	case envOutput:
+		// The variables are named after the secret, such as SECRET_LENGTH, and numbered for multiple values, such as SECRET_1
+		suffix := ""
+		if outputCount > 1 {
+			suffix = fmt.Sprintf("_%d", index+1)
+		}
+		var builder strings.Builder
+		for _, field := range record.namedFields() {
+			if field.value == "" {
+				continue
+			}
+			name := "SECRET"
+			if field.name != "secret" {
+				name += "_" + strings.ToUpper(field.name)
+			}
+			builder.WriteString(name + suffix + "=" + quoteShell(field.value) + "\n")
+		}
+		return builder.String()
+	case yamlOutput:
+		// JSON strings are also valid double-quoted YAML scalars, which safely quotes any secret
+		var builder strings.Builder
+		prefix := "- "
+		for _, field := range record.namedFields() {
+			if field.value == "" {
+				continue
+			}
+			value := field.value
+			if field.quoted {
+				value = marshalJSON(value, "")
+			}
+			builder.WriteString(prefix + field.name + ": " + value + "\n")
+			prefix = "  "
+		}
+		return builder.String()
+	default:
+		separator := outputSeparator
+		if outputNull {
+			separator = "\x00"
+		}
+		if record.Secret == "" {
+			return record.Hash + separator
+		} else if record.Hash == "" {
+			return record.Secret + separator
+		}
+		return record.Secret + "\t" + record.Hash + separator
+	}
+}
+
+// outputFieldNames are the names of the fields of a record, in their order of output.
+var outputFieldNames = []string{"secret", "hash", "length", "entropy_bits", "charset", "policy", "timestamp"}
+
+type outputField struct {
+	name   string
+	value  string
+	quoted bool
+}
+
+func (record *outputRecord) namedFields() []outputField {
+	fields := record.fields()
+	namedFields := make([]outputField, len(fields))
+	for i := range fields {
+		// The length and entropy are numbers
+		namedFields[i] = outputField{name: outputFieldNames[i], value: fields[i], quoted: i != 2 && i != 3}
+	}
+	return namedFields
+}
+
+func (record *outputRecord) fields() []string {
+	length := ""
+	if record.Length > 0 {
+		length = fmt.Sprint(record.Length)
+	}
+	return []string{
+		record.Secret,
+		record.Hash,
+		length,
+		fmt.Sprint(record.EntropyBits),
+		record.Charset,
+		record.Policy,
+		record.Timestamp,
+	}
+}
+
+// formatStringObject formats the non-empty fields of the record as a flat JSON object of strings, where each line after the
+// first is prefixed with prefix.
+func formatStringObject(record *outputRecord, prefix string) string {
+	var builder strings.Builder
+	builder.WriteString("{")
+	for _, field := range record.namedFields() {
+		if field.value == "" {
+			continue
+		}
+		if builder.Len() > 1 {
+			builder.WriteString(",")
+		}
+		builder.WriteString("\n" + prefix + "  " + marshalJSON(field.name, "") + ": " + marshalJSON(field.value, ""))
+	}
+	builder.WriteString("\n" + prefix + "}")
+	return builder.String()
+}
+
+// marshalJSON returns the JSON of a value without a trailing newline, indented if indent is not empty. Unlike
+// json.Marshal, it leaves characters such as < and & as they are, since secrets are not embedded in HTML.
+func marshalJSON(value interface{}, indent string) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if indent != "" {
+		encoder.SetIndent(indent, "  ")
+	}
+	encoder.Encode(value)
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// quoteShell quotes a value in single quotes for a POSIX shell, so that the env output can be sourced.
+func quoteShell(value string) string {
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
@@ -28,14 +28,22 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 )
 
+// errReported is returned by commands that have already reported why they failed, such as a
+// check that prints its violations, so that Execute only exits with a failure status.
+var errReported = errors.New("The command failed")
+
 var rootCmd = &cobra.Command{
 	Use:   "strongpass",
 	Short: "StrongPass is a strong and safe password generator",
 	Long: `A strong and safe password generator that gives you
 			a bunch of options for your specific requirements.`,
+	// Errors are printed by Execute, and are not caused by incorrect usage once the flags are parsed
+	SilenceErrors: true,
+	SilenceUsage:  true,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("Use `strongpass --help` for more information")
 	},
@@ -44,7 +52,9 @@ var rootCmd = &cobra.Command{
 // Execute executes the CLI.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
+		if err != errReported {
+			fmt.Fprintln(os.Stderr, err)
+		}
 		os.Exit(1)
 	}
 }
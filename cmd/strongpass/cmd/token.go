@@ -0,0 +1,66 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/token"
+)
+
+var tokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Verifies API tokens",
+	Long:  "Verifies API tokens that were generated with generate-token.",
+}
+
+var tokenVerifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verifies the format and checksum of an API token",
+	Long: `Verifies the format and checksum of an API token offline.
+The token is prompted for without echo, or read from the first line of stdin when it is not a terminal.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		value, err := readPassword("Token: ")
+		if err != nil {
+			return err
+		}
+
+		if err := token.Verify(value, tokenVerifyPrefix); err != nil {
+			fmt.Printf("The token is not valid: %s\n", err)
+			return errReported
+		}
+		fmt.Println("The token is valid")
+		return nil
+	},
+}
+var tokenVerifyPrefix string
+
+func init() {
+	tokenVerifyCmd.Flags().StringVar(&tokenVerifyPrefix, "prefix", "", "The prefix that the token must have, any prefix is accepted if empty")
+	tokenCmd.AddCommand(tokenVerifyCmd)
+	rootCmd.AddCommand(tokenCmd)
+}
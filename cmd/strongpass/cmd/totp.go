@@ -0,0 +1,123 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/whinarn/strongpass/pkg/otp"
+)
+
+var totpCmd = &cobra.Command{
+	Use:   "totp",
+	Short: "Generates and verifies TOTP codes",
+	Long: `Generates and verifies TOTP codes of a secret.
+The base32 secret or otpauth:// URI is prompted for without echo, or read from the first line of stdin when it is not a terminal.
+The parameters of a URI override the digits, period and algorithm flags.`,
+}
+
+var totpCodeCmd = &cobra.Command{
+	Use:   "code",
+	Short: "Prints the current TOTP code of a secret",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := readTOTPKey()
+		if err != nil {
+			return err
+		}
+
+		code, err := key.Code(time.Now())
+		if err != nil {
+			return err
+		}
+		fmt.Println(code)
+		return nil
+	},
+}
+
+var totpVerifyCmd = &cobra.Command{
+	Use:   "verify <code>",
+	Short: "Verifies a TOTP code of a secret",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		key, err := readTOTPKey()
+		if err != nil {
+			return err
+		}
+
+		valid, err := key.Verify(args[0], time.Now(), totpSkew)
+		if err != nil {
+			return err
+		}
+		if !valid {
+			fmt.Println("The code is not valid")
+			return errReported
+		}
+		fmt.Println("The code is valid")
+		return nil
+	},
+}
+var totpDigits int
+var totpPeriod int
+var totpAlgorithm string
+var totpSkew int
+
+func init() {
+	totpCmd.PersistentFlags().IntVar(&totpDigits, "digits", 6, "The number of digits of the codes")
+	totpCmd.PersistentFlags().IntVar(&totpPeriod, "period", 30, "The period of the codes in seconds")
+	totpCmd.PersistentFlags().StringVar(&totpAlgorithm, "algorithm", string(otp.SHA1), "The algorithm of the codes, either SHA1, SHA256 or SHA512")
+	totpVerifyCmd.Flags().IntVar(&totpSkew, "skew", 1, "The number of periods before and after the current one that are also accepted")
+	totpCmd.AddCommand(totpCodeCmd)
+	totpCmd.AddCommand(totpVerifyCmd)
+	rootCmd.AddCommand(totpCmd)
+}
+
+func readTOTPKey() (*otp.Key, error) {
+	input, err := readPassword("Secret: ")
+	if err != nil {
+		return nil, err
+	}
+	if strings.HasPrefix(input, "otpauth://") {
+		return otp.ParseURI(input)
+	}
+
+	secret, err := otp.DecodeSecret(input)
+	if err != nil {
+		return nil, err
+	}
+	algorithm, err := otp.ParseAlgorithm(totpAlgorithm)
+	if err != nil {
+		return nil, err
+	}
+	return &otp.Key{
+		Secret:    secret,
+		Digits:    totpDigits,
+		Period:    totpPeriod,
+		Algorithm: algorithm,
+	}, nil
+}
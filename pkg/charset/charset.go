@@ -0,0 +1,311 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package charset parses charset expressions into sets of characters.
+//
+// A charset expression is a union of the following items:
+//
+//	abc        literal characters
+//	a-z        an inclusive range of characters
+//	[:punct:]  a POSIX character class of ASCII characters
+//	\p{Lu}     a Unicode category or script, \pL is short for \p{L}
+//	\-         an escaped character, which is always literal
+//
+// A hyphen that does not form a range, such as at the start or the end, is literal.
+// Sets are subtracted with "--", so "a-z0-9--01" is the lower-case letters and the digits
+// except 0 and 1. Subtractions are applied from left to right.
+//
+// The resulting characters must be printable, which are letters, marks, numbers, punctuation,
+// symbols and the ASCII space, so classes such as [:cntrl:] and \p{Cs} can only be subtracted.
+package charset
+
+import (
+	"fmt"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// posixClasses are the POSIX character classes, limited to ASCII.
+var posixClasses = map[string]func(r rune) bool{
+	"alnum":  func(r rune) bool { return isASCIILetter(r) || isASCIIDigit(r) },
+	"alpha":  isASCIILetter,
+	"blank":  func(r rune) bool { return r == ' ' || r == '\t' },
+	"cntrl":  func(r rune) bool { return r < 0x20 || r == 0x7f },
+	"digit":  isASCIIDigit,
+	"graph":  func(r rune) bool { return r > 0x20 && r < 0x7f },
+	"lower":  func(r rune) bool { return r >= 'a' && r <= 'z' },
+	"print":  func(r rune) bool { return r >= 0x20 && r < 0x7f },
+	"punct":  func(r rune) bool { return r > 0x20 && r < 0x7f && !isASCIILetter(r) && !isASCIIDigit(r) },
+	"space":  func(r rune) bool { return r == ' ' || r >= '\t' && r <= '\r' },
+	"upper":  func(r rune) bool { return r >= 'A' && r <= 'Z' },
+	"xdigit": func(r rune) bool { return isASCIIDigit(r) || r >= 'a' && r <= 'f' || r >= 'A' && r <= 'F' },
+}
+
+type parser struct {
+	expression []rune
+	position   int
+}
+
+// Parse parses a charset expression into its distinct characters, in order of first appearance.
+func Parse(expression string) ([]rune, error) {
+	p := &parser{expression: []rune(expression)}
+
+	result, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	for p.consumeOperator() {
+		subtracted, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		result = subtract(result, subtracted)
+	}
+
+	if len(result) == 0 {
+		return nil, errors.New("The charset expression has no characters")
+	}
+	for _, r := range result {
+		if !unicode.IsPrint(r) {
+			return nil, errors.Errorf("The charset expression has the non-printable character %U", r)
+		}
+	}
+	return result, nil
+}
+
+// parseUnion parses items until the end of the expression or a subtraction operator.
+func (p *parser) parseUnion() ([]rune, error) {
+	start := p.position
+	var result []rune
+	seen := make(map[rune]bool)
+	for p.position < len(p.expression) && !p.atOperator() {
+		runes, err := p.parseItem()
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range runes {
+			if !seen[r] {
+				seen[r] = true
+				result = append(result, r)
+			}
+		}
+	}
+
+	if p.position == start {
+		return nil, p.errorf("Expected characters")
+	}
+	return result, nil
+}
+
+func (p *parser) parseItem() ([]rune, error) {
+	if p.hasPrefix("[:") {
+		return p.parsePOSIXClass()
+	}
+	if p.hasPrefix("\\p") {
+		return p.parseUnicodeClass()
+	}
+
+	first, err := p.parseChar()
+	if err != nil {
+		return nil, err
+	}
+
+	// A hyphen followed by a character, that is not the start of another item, forms a range
+	if p.peek() == '-' && !p.atOperator() && p.position+1 < len(p.expression) &&
+		!p.hasPrefixAt(p.position+1, "[:") && !p.hasPrefixAt(p.position+1, "\\p") &&
+		!p.hasPrefixAt(p.position+1, "--") {
+		rangeStart := p.position
+		p.position++
+		last, err := p.parseChar()
+		if err != nil {
+			return nil, err
+		}
+		if last < first {
+			p.position = rangeStart
+			return nil, p.errorf("The range %c-%c is reversed", first, last)
+		}
+
+		runes := make([]rune, 0, last-first+1)
+		for r := first; r <= last; r++ {
+			runes = append(runes, r)
+		}
+		return runes, nil
+	}
+	return []rune{first}, nil
+}
+
+func (p *parser) parseChar() (rune, error) {
+	r := p.expression[p.position]
+	p.position++
+	if r != '\\' {
+		return r, nil
+	}
+
+	if p.position >= len(p.expression) {
+		return 0, p.errorf("The expression ends with an unfinished escape")
+	}
+	r = p.expression[p.position]
+	p.position++
+	return r, nil
+}
+
+func (p *parser) parsePOSIXClass() ([]rune, error) {
+	start := p.position
+	end := p.indexFrom(p.position+2, ":]")
+	if end < 0 {
+		return nil, p.errorf("The POSIX class is not terminated with :]")
+	}
+
+	name := string(p.expression[start+2 : end])
+	isMember, ok := posixClasses[name]
+	if !ok {
+		return nil, p.errorf("Unknown POSIX class [:%s:]", name)
+	}
+	p.position = end + 2
+
+	var runes []rune
+	for r := rune(0); r < 0x80; r++ {
+		if isMember(r) {
+			runes = append(runes, r)
+		}
+	}
+	return runes, nil
+}
+
+func (p *parser) parseUnicodeClass() ([]rune, error) {
+	p.position += 2
+	if p.position >= len(p.expression) {
+		return nil, p.errorf("Expected a Unicode category or script after \\p")
+	}
+
+	var name string
+	if p.expression[p.position] == '{' {
+		end := p.indexFrom(p.position, "}")
+		if end < 0 {
+			return nil, p.errorf("The Unicode class is not terminated with }")
+		}
+		name = string(p.expression[p.position+1 : end])
+		p.position = end + 1
+	} else {
+		name = string(p.expression[p.position])
+		p.position++
+	}
+
+	table, ok := unicode.Categories[name]
+	if !ok {
+		table, ok = unicode.Scripts[name]
+	}
+	if !ok {
+		return nil, p.errorf("Unknown Unicode category or script %s", name)
+	}
+	return tableRunes(table), nil
+}
+
+func (p *parser) consumeOperator() bool {
+	if !p.atOperator() {
+		return false
+	}
+	p.position += 2
+	return true
+}
+
+func (p *parser) atOperator() bool {
+	return p.hasPrefix("--")
+}
+
+func (p *parser) peek() rune {
+	if p.position >= len(p.expression) {
+		return 0
+	}
+	return p.expression[p.position]
+}
+
+func (p *parser) hasPrefix(prefix string) bool {
+	return p.hasPrefixAt(p.position, prefix)
+}
+
+func (p *parser) hasPrefixAt(position int, prefix string) bool {
+	prefixRunes := []rune(prefix)
+	if position+len(prefixRunes) > len(p.expression) {
+		return false
+	}
+	for i, r := range prefixRunes {
+		if p.expression[position+i] != r {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *parser) indexFrom(position int, substring string) int {
+	for i := position; i < len(p.expression); i++ {
+		if p.hasPrefixAt(i, substring) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return errors.Errorf("Invalid charset expression at position %d: %s", p.position+1, fmt.Sprintf(format, args...))
+}
+
+func tableRunes(table *unicode.RangeTable) []rune {
+	var runes []rune
+	for _, r16 := range table.R16 {
+		for r := rune(r16.Lo); r <= rune(r16.Hi); r += rune(r16.Stride) {
+			runes = append(runes, r)
+		}
+	}
+	for _, r32 := range table.R32 {
+		for r := rune(r32.Lo); r <= rune(r32.Hi); r += rune(r32.Stride) {
+			runes = append(runes, r)
+		}
+	}
+	return runes
+}
+
+func subtract(runes []rune, subtracted []rune) []rune {
+	removed := make(map[rune]bool, len(subtracted))
+	for _, r := range subtracted {
+		removed[r] = true
+	}
+
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if !removed[r] {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func isASCIILetter(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
+
+func isASCIIDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
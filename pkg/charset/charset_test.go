@@ -0,0 +1,137 @@
+package charset_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/charset"
+)
+
+func TestParseLiteralsShouldSucceed(t *testing.T) {
+	runes, err := charset.Parse("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("abc"), runes)
+}
+
+func TestParseDuplicatesShouldBeRemoved(t *testing.T) {
+	runes, err := charset.Parse("aabbca")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("abc"), runes)
+}
+
+func TestParseRangesShouldSucceed(t *testing.T) {
+	runes, err := charset.Parse("a-f0-3")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("abcdef0123"), runes)
+}
+
+func TestParseLiteralHyphensShouldSucceed(t *testing.T) {
+	runes, err := charset.Parse("-ab-")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("-ab"), runes)
+
+	runes, err = charset.Parse(`a\-c`)
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("a-c"), runes)
+}
+
+func TestParseReversedRangeShouldFail(t *testing.T) {
+	_, err := charset.Parse("z-a")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "reversed")
+}
+
+func TestParsePOSIXClassesShouldSucceed(t *testing.T) {
+	runes, err := charset.Parse("[:digit:]")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("0123456789"), runes)
+
+	runes, err = charset.Parse("[:punct:]")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~"), runes)
+
+	runes, err = charset.Parse("[:xdigit:]")
+	assert.NoError(t, err)
+	assert.Len(t, runes, 22)
+}
+
+func TestParseUnknownPOSIXClassShouldFail(t *testing.T) {
+	_, err := charset.Parse("[:emoji:]")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown POSIX class")
+}
+
+func TestParseUnterminatedPOSIXClassShouldFail(t *testing.T) {
+	_, err := charset.Parse("a[:digit")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "position 2")
+}
+
+func TestParseUnicodeClassesShouldSucceed(t *testing.T) {
+	runes, err := charset.Parse(`\p{Greek}--\p{Lu}`)
+	assert.NoError(t, err)
+	assert.Contains(t, runes, 'α')
+	assert.NotContains(t, runes, 'Α')
+
+	runes, err = charset.Parse(`\pN`)
+	assert.NoError(t, err)
+	assert.Contains(t, runes, '7')
+	assert.Contains(t, runes, '٣')
+}
+
+func TestParseUnknownUnicodeClassShouldFail(t *testing.T) {
+	_, err := charset.Parse(`\p{Klingon}`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown Unicode category or script Klingon")
+}
+
+func TestParseSubtractionShouldSucceed(t *testing.T) {
+	runes, err := charset.Parse("a-z0-9--01--aeiou")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune("bcdfghjklmnpqrstvwxyz23456789"), runes)
+}
+
+func TestParseEmptySubtractionShouldFail(t *testing.T) {
+	_, err := charset.Parse("a-z--")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Expected characters")
+}
+
+func TestParseEmptyResultShouldFail(t *testing.T) {
+	_, err := charset.Parse("abc--a-c")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no characters")
+}
+
+func TestParseUnfinishedEscapeShouldFail(t *testing.T) {
+	_, err := charset.Parse(`abc\`)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unfinished escape")
+}
+
+func TestParseNonPrintableCharactersShouldFail(t *testing.T) {
+	expressions := []string{"[:cntrl:]", "a\tb", `\p{Cs}`, "[:space:]", "a\u200bb"}
+	for _, expression := range expressions {
+		_, err := charset.Parse(expression)
+		if assert.Error(t, err, expression) {
+			assert.Contains(t, err.Error(), "non-printable", expression)
+		}
+	}
+}
+
+func TestParseSubtractedNonPrintableCharactersShouldSucceed(t *testing.T) {
+	runes, err := charset.Parse("[:space:]--[:cntrl:]")
+	assert.NoError(t, err)
+	assert.Equal(t, []rune(" "), runes)
+}
+
+func TestParseManyLiteralsShouldSucceed(t *testing.T) {
+	var builder strings.Builder
+	for r := rune(0x4e00); r < 0x4e00+20000; r++ {
+		builder.WriteRune(r)
+	}
+	runes, err := charset.Parse(builder.String())
+	assert.NoError(t, err)
+	assert.Len(t, runes, 20000)
+}
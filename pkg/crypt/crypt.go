@@ -0,0 +1,169 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package crypt hashes passwords in the formats of system password files, such as /etc/shadow
+// and htpasswd files. The hashes are in the modular crypt format, or in the PHC string format
+// for Argon2id.
+package crypt
+
+import (
+	"crypto/subtle"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/rand"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Algorithm is a password hashing algorithm.
+type Algorithm string
+
+const (
+	// SHA512Crypt is the SHA-512 based crypt of glibc, with the $6$ prefix.
+	SHA512Crypt Algorithm = "sha512-crypt"
+	// Bcrypt is bcrypt with the default cost, with the $2a$ prefix.
+	Bcrypt Algorithm = "bcrypt"
+	// Argon2id is Argon2id with the default parameters, in the PHC string format.
+	Argon2id Algorithm = "argon2id"
+	// APR1 is the MD5 based crypt of Apache, with the $apr1$ prefix. It is weak, but it is the
+	// only algorithm of htpasswd files that every web server supports.
+	APR1 Algorithm = "apr1"
+)
+
+// BcryptMaxPasswordLength is the number of bytes of a password that bcrypt uses, where the
+// rest of the password is ignored.
+const BcryptMaxPasswordLength = 72
+
+// cryptAlphabet has the characters of the base64 encoding of the crypt formats.
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Algorithms returns all the algorithms.
+func Algorithms() []Algorithm {
+	return []Algorithm{SHA512Crypt, Bcrypt, Argon2id, APR1}
+}
+
+// ParseAlgorithm parses the name of an algorithm.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	for _, algorithm := range Algorithms() {
+		if name == string(algorithm) {
+			return algorithm, nil
+		}
+	}
+	return "", errors.Errorf("Unknown hash algorithm %q, expected sha512-crypt, bcrypt, argon2id or apr1", name)
+}
+
+// Hash hashes a password with a random salt.
+func Hash(password string, algorithm Algorithm) (string, error) {
+	switch algorithm {
+	case SHA512Crypt:
+		salt, err := generateSalt(shaCryptMaxSaltLength)
+		if err != nil {
+			return "", err
+		}
+		return sha512Crypt(password, salt, shaCryptDefaultRounds, false), nil
+	case Bcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to hash the password with bcrypt")
+		}
+		return string(hash), nil
+	case Argon2id:
+		return hashArgon2id(password)
+	case APR1:
+		salt, err := generateSalt(md5CryptMaxSaltLength)
+		if err != nil {
+			return "", err
+		}
+		return apr1(password, salt), nil
+	default:
+		return "", errors.Errorf("Unknown hash algorithm %q", algorithm)
+	}
+}
+
+// Verify returns true if the hash is a hash of the password, in any of the formats that Hash
+// returns. It returns an error if the format of the hash is not recognized or is invalid, or if
+// its cost exceeds a limit, which is 1000000 rounds for SHA-crypt, and 1 GiB of memory or 64
+// passes for Argon2id.
+func Verify(password, hash string) (bool, error) {
+	var expected string
+	switch {
+	case strings.HasPrefix(hash, sha512CryptPrefix):
+		salt, rounds, customRounds, err := parseSHACrypt(hash, sha512CryptPrefix)
+		if err != nil {
+			return false, err
+		}
+		expected = sha512Crypt(password, salt, rounds, customRounds)
+	case strings.HasPrefix(hash, "$2a$"), strings.HasPrefix(hash, "$2b$"), strings.HasPrefix(hash, "$2y$"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err == bcrypt.ErrMismatchedHashAndPassword {
+			return false, nil
+		} else if err != nil {
+			return false, errors.Wrap(err, "The bcrypt hash is invalid")
+		}
+		return true, nil
+	case strings.HasPrefix(hash, argon2idPrefix):
+		return verifyArgon2id(password, hash)
+	case strings.HasPrefix(hash, apr1Prefix):
+		parts := strings.Split(hash, "$")
+		if len(parts) != 4 {
+			return false, errors.New("The apr1 hash is invalid")
+		}
+		expected = apr1(password, parts[2])
+	default:
+		return false, errors.New("The format of the hash is not recognized")
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1, nil
+}
+
+func generateSalt(length int) (string, error) {
+	salt := make([]byte, length)
+	for i := range salt {
+		index, err := rand.IntnE(len(cryptAlphabet))
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to generate a salt")
+		}
+		salt[i] = cryptAlphabet[index]
+	}
+	return string(salt), nil
+}
+
+// encodeCrypt encodes bytes in the base64 encoding of the crypt formats, where the bytes are
+// taken in groups of three in the specified order and each group is encoded from its lowest bits.
+func encodeCrypt(data []byte, order []int) string {
+	var encoded strings.Builder
+	for i := 0; i < len(order); i += 3 {
+		var value uint
+		count := 0
+		for j := i; j < i+3 && j < len(order); j++ {
+			value = value<<8 | uint(data[order[j]])
+			count++
+		}
+		// A group of n bytes is encoded as n+1 characters
+		for j := 0; j <= count; j++ {
+			encoded.WriteByte(cryptAlphabet[value&0x3f])
+			value >>= 6
+		}
+	}
+	return encoded.String()
+}
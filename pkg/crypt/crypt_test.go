@@ -0,0 +1,95 @@
+package crypt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/crypt"
+)
+
+func TestVerifyKnownHashesShouldSucceed(t *testing.T) {
+	tests := []struct {
+		password string
+		hash     string
+	}{
+		// The test vectors of the SHA-crypt specification
+		{"Hello world!", "$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1"},
+		{"Hello world!", "$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v."},
+		{"the minimum number is still observed", "$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX."},
+		// Hashes of openssl passwd -apr1
+		{"myPassword", "$apr1$r31.....$HqJZimcKQFAMYayBlzkrA/"},
+		{"a much longer password of 40 chars....", "$apr1$xxxxxxxx$VktcVUrqj10OXcXENCnJb1"},
+		// The test vectors of jBCrypt
+		{"", "$2a$06$DCq7YPn5Rq63x1Lad4cll.TV4S6ytwfsfvkgY8jIucDrjc8deX1s."},
+		{"a", "$2a$06$m0CrhHm10qJ3lXRY.5zDGO3rS2KdeeWLuGmsfGlMfOxih58VYVfxe"},
+		// The test vector of the Argon2 reference implementation
+		{"password", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"},
+	}
+
+	for _, test := range tests {
+		valid, err := crypt.Verify(test.password, test.hash)
+		assert.NoError(t, err, test.hash)
+		assert.True(t, valid, test.hash)
+
+		valid, err = crypt.Verify(test.password+"x", test.hash)
+		assert.NoError(t, err, test.hash)
+		assert.False(t, valid, test.hash)
+	}
+}
+
+func TestSHA256CryptDigestShouldSucceed(t *testing.T) {
+	// The test vector of the SHA-crypt specification, which is $5$saltstring$5B8vYYiY...
+	digest := crypt.SHA256CryptDigest("Hello world!", []byte("saltstring"), 5000)
+	assert.Equal(t, "5B8vYYiY.CVt1RlTTf8KbXBH3hsxY/GNooZaBBGWEc5", digest)
+}
+
+func TestHashShouldSucceed(t *testing.T) {
+	prefixes := map[crypt.Algorithm]string{
+		crypt.SHA512Crypt: "$6$",
+		crypt.Bcrypt:      "$2a$10$",
+		crypt.Argon2id:    "$argon2id$v=19$m=65536,t=3,p=4$",
+		crypt.APR1:        "$apr1$",
+	}
+	for _, algorithm := range crypt.Algorithms() {
+		hash, err := crypt.Hash("correct horse battery staple", algorithm)
+		assert.NoError(t, err, algorithm)
+		assert.True(t, strings.HasPrefix(hash, prefixes[algorithm]), hash)
+
+		valid, err := crypt.Verify("correct horse battery staple", hash)
+		assert.NoError(t, err, algorithm)
+		assert.True(t, valid, algorithm)
+
+		// The salts are random
+		other, err := crypt.Hash("correct horse battery staple", algorithm)
+		assert.NoError(t, err, algorithm)
+		assert.NotEqual(t, hash, other, algorithm)
+	}
+}
+
+func TestParseAlgorithmShouldFail(t *testing.T) {
+	_, err := crypt.ParseAlgorithm("md5")
+	assert.Error(t, err)
+	algorithm, err := crypt.ParseAlgorithm("sha512-crypt")
+	assert.NoError(t, err)
+	assert.Equal(t, crypt.SHA512Crypt, algorithm)
+}
+
+func TestVerifyShouldFail(t *testing.T) {
+	invalidHashes := []string{
+		"",
+		"$1$salt$hash",
+		"$6$rounds=many$salt$hash",
+		"$6$rounds=999999999$salt$hash",
+		"$apr1$salt",
+		"$2a$10$short",
+		"$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ",
+		"$argon2id$v=16$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=4294967295,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+		"$argon2id$v=19$m=65536,t=4294967295,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc",
+	}
+	for _, hash := range invalidHashes {
+		_, err := crypt.Verify("password", hash)
+		assert.Error(t, err, hash)
+	}
+}
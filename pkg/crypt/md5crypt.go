@@ -0,0 +1,95 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package crypt
+
+import (
+	"crypto/md5"
+)
+
+// Credits: https://github.com/freebsd/freebsd-src/blob/main/lib/libcrypt/crypt-md5.c
+
+const (
+	apr1Prefix            = "$apr1$"
+	md5CryptMaxSaltLength = 8
+	md5CryptRounds        = 1000
+)
+
+// md5CryptOrder is the order that the bytes of an MD5 crypt digest are encoded in.
+var md5CryptOrder = []int{0, 6, 12, 1, 7, 13, 2, 8, 14, 3, 9, 15, 4, 10, 5, 11}
+
+// apr1 returns the Apache MD5 crypt hash of a password, which only differs from the MD5 crypt
+// of FreeBSD by its prefix.
+func apr1(password, salt string) string {
+	if len(salt) > md5CryptMaxSaltLength {
+		salt = salt[:md5CryptMaxSaltLength]
+	}
+	return apr1Prefix + salt + "$" + encodeCrypt(md5CryptDigest([]byte(password), []byte(salt), []byte(apr1Prefix)), md5CryptOrder)
+}
+
+func md5CryptDigest(password, salt, prefix []byte) []byte {
+	h := md5.New()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	alternate := h.Sum(nil)
+
+	h = md5.New()
+	h.Write(password)
+	h.Write(prefix)
+	h.Write(salt)
+	h.Write(repeatBytes(alternate, len(password)))
+	// This is a quirk of the original implementation, which writes a zero byte or the first
+	// byte of the password for each bit of the length
+	for length := len(password); length > 0; length >>= 1 {
+		if length&1 != 0 {
+			h.Write([]byte{0})
+		} else {
+			h.Write(password[:1])
+		}
+	}
+	digest := h.Sum(nil)
+
+	for i := 0; i < md5CryptRounds; i++ {
+		h = md5.New()
+		if i&1 != 0 {
+			h.Write(password)
+		} else {
+			h.Write(digest)
+		}
+		if i%3 != 0 {
+			h.Write(salt)
+		}
+		if i%7 != 0 {
+			h.Write(password)
+		}
+		if i&1 != 0 {
+			h.Write(digest)
+		} else {
+			h.Write(password)
+		}
+		digest = h.Sum(digest[:0])
+	}
+	return digest
+}
@@ -0,0 +1,182 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package crypt
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Credits: https://www.akkadia.org/drepper/SHA-crypt.txt
+
+const (
+	sha512CryptPrefix     = "$6$"
+	shaCryptRoundsPrefix  = "rounds="
+	shaCryptMaxSaltLength = 16
+	shaCryptDefaultRounds = 5000
+	shaCryptMinRounds     = 1000
+	shaCryptMaxRounds     = 999999999
+
+	// shaCryptMaxVerifyRounds bounds the rounds of a hash to verify, since a crafted hash could
+	// otherwise make the verification run for minutes. It is above the common defaults, such as
+	// the 656000 rounds of passlib.
+	shaCryptMaxVerifyRounds = 1000000
+)
+
+// sha256CryptOrder is the order that the bytes of a SHA-256 crypt digest are encoded in.
+var sha256CryptOrder = []int{
+	0, 10, 20, 21, 1, 11, 12, 22, 2, 3, 13, 23, 24, 4, 14, 15, 25, 5, 6, 16, 26, 27, 7, 17,
+	18, 28, 8, 9, 19, 29, 31, 30,
+}
+
+// sha512CryptOrder is the order that the bytes of a SHA-512 crypt digest are encoded in.
+var sha512CryptOrder = []int{
+	0, 21, 42, 22, 43, 1, 44, 2, 23, 3, 24, 45, 25, 46, 4, 47, 5, 26, 6, 27, 48, 28, 49, 7,
+	50, 8, 29, 9, 30, 51, 31, 52, 10, 53, 11, 32, 12, 33, 54, 34, 55, 13, 56, 14, 35, 15, 36, 57,
+	37, 58, 16, 59, 17, 38, 18, 39, 60, 40, 61, 19, 62, 20, 41, 63,
+}
+
+// sha512Crypt returns the SHA-512 crypt hash of a password. The rounds are only written into the
+// hash if customRounds is true, in which case they are also clamped to the valid range.
+func sha512Crypt(password, salt string, rounds int, customRounds bool) string {
+	if len(salt) > shaCryptMaxSaltLength {
+		salt = salt[:shaCryptMaxSaltLength]
+	}
+	if customRounds {
+		if rounds < shaCryptMinRounds {
+			rounds = shaCryptMinRounds
+		} else if rounds > shaCryptMaxRounds {
+			rounds = shaCryptMaxRounds
+		}
+	}
+
+	digest := shaCryptDigest(sha512.New, []byte(password), []byte(salt), rounds)
+	var hash strings.Builder
+	hash.WriteString(sha512CryptPrefix)
+	if customRounds {
+		hash.WriteString(shaCryptRoundsPrefix + strconv.Itoa(rounds) + "$")
+	}
+	hash.WriteString(salt + "$" + encodeCrypt(digest, sha512CryptOrder))
+	return hash.String()
+}
+
+// SHA256CryptDigest returns the encoded SHA-256 crypt digest of a password, which is the last
+// field of a $5$ hash. Unlike in a $5$ hash, the salt is used in full, as in the
+// caching_sha2_password verifiers of MySQL.
+func SHA256CryptDigest(password string, salt []byte, rounds int) string {
+	return encodeCrypt(shaCryptDigest(sha256.New, []byte(password), salt, rounds), sha256CryptOrder)
+}
+
+// shaCryptDigest returns the digest of the SHA-crypt algorithm with the specified hash function.
+func shaCryptDigest(newHash func() hash.Hash, password, salt []byte, rounds int) []byte {
+	h := newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(password)
+	alternate := h.Sum(nil)
+
+	h = newHash()
+	h.Write(password)
+	h.Write(salt)
+	h.Write(repeatBytes(alternate, len(password)))
+	for length := len(password); length > 0; length >>= 1 {
+		if length&1 != 0 {
+			h.Write(alternate)
+		} else {
+			h.Write(password)
+		}
+	}
+	digest := h.Sum(nil)
+
+	h = newHash()
+	for i := 0; i < len(password); i++ {
+		h.Write(password)
+	}
+	passwordSequence := repeatBytes(h.Sum(nil), len(password))
+
+	h = newHash()
+	for i := 0; i < 16+int(digest[0]); i++ {
+		h.Write(salt)
+	}
+	saltSequence := repeatBytes(h.Sum(nil), len(salt))
+
+	for i := 0; i < rounds; i++ {
+		h = newHash()
+		if i&1 != 0 {
+			h.Write(passwordSequence)
+		} else {
+			h.Write(digest)
+		}
+		if i%3 != 0 {
+			h.Write(saltSequence)
+		}
+		if i%7 != 0 {
+			h.Write(passwordSequence)
+		}
+		if i&1 != 0 {
+			h.Write(digest)
+		} else {
+			h.Write(passwordSequence)
+		}
+		digest = h.Sum(digest[:0])
+	}
+	return digest
+}
+
+// parseSHACrypt returns the salt and rounds of a SHA-crypt hash to verify, where the rounds are at
+// most shaCryptMaxVerifyRounds.
+func parseSHACrypt(hash, prefix string) (salt string, rounds int, customRounds bool, err error) {
+	parts := strings.Split(hash[len(prefix):], "$")
+	rounds = shaCryptDefaultRounds
+	if len(parts) == 3 && strings.HasPrefix(parts[0], shaCryptRoundsPrefix) {
+		rounds, err = strconv.Atoi(parts[0][len(shaCryptRoundsPrefix):])
+		if err != nil {
+			return "", 0, false, errors.New("The rounds of the SHA-crypt hash are not a number")
+		}
+		if rounds > shaCryptMaxVerifyRounds {
+			return "", 0, false, errors.Errorf("The rounds of the SHA-crypt hash exceed the limit of %d", shaCryptMaxVerifyRounds)
+		}
+		customRounds = true
+		parts = parts[1:]
+	}
+	if len(parts) != 2 {
+		return "", 0, false, errors.New("The SHA-crypt hash is invalid")
+	}
+	return parts[0], rounds, customRounds, nil
+}
+
+// repeatBytes returns data repeated to the specified length.
+func repeatBytes(data []byte, length int) []byte {
+	repeated := make([]byte, length)
+	for i := range repeated {
+		repeated[i] = data[i%len(data)]
+	}
+	return repeated
+}
@@ -0,0 +1,266 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package derive derives site-specific passwords from a master password, in the style of
+// LessPass. Nothing has to be stored, since the same inputs always derive the same password.
+//
+// The mapping from the derived key to the password is frozen for each version of the derivation,
+// and is independent of how the generator draws random passwords. Version 2 works as follows:
+//
+//  1. The key is derived with Argon2id from the master password and a salt of the version, site,
+//     login and counter. It is 32 bytes plus 8 bytes for each character of the maximum length.
+//  2. The key is read as a big-endian integer. Each choice below takes the remainder of dividing
+//     it by the number of options, and keeps the quotient for the next choice.
+//  3. The length is chosen from the minimum to the maximum length.
+//  4. The minimum number of characters of each class is chosen from the class, in class order.
+//  5. The remaining characters are chosen from the character set, in order, without the
+//     characters of the classes that have reached their maximum.
+//  6. The characters of step 4 are inserted in order, each at a chosen position from zero to
+//     the number of characters so far.
+package derive
+
+import (
+	"encoding/binary"
+	"math/big"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/generator"
+	"golang.org/x/crypto/argon2"
+)
+
+// saltPrefix identifies the derivation scheme, which changes if the derivation ever has to change.
+const saltPrefix = "strongpass-derive-v2"
+
+// Params are the Argon2id parameters of the derivation.
+type Params struct {
+	// Time is the number of passes over the memory.
+	Time uint32
+	// Memory is the memory in KiB.
+	Memory uint32
+	// Threads is the degree of parallelism, which affects the derived key.
+	Threads uint8
+}
+
+// Config is the configuration of a derived password.
+type Config struct {
+	Site    string
+	Login   string
+	Counter uint32
+
+	// Policy is the password policy that the derived password satisfies, or the default generator
+	// configuration if nil. Its Rand field is ignored, and pronounceable policies are not supported.
+	// Unless the policy has a custom character set or classes, derived passwords are only made of
+	// ASCII characters, which leaves out the special symbols § ½ ¤ £ that are hard to type.
+	Policy *generator.Config
+	// Params are the parameters of the key derivation, or the default parameters if nil.
+	Params *Params
+}
+
+// DefaultParams returns the default Argon2id parameters, which take 64 MiB of memory.
+func DefaultParams() *Params {
+	return &Params{
+		Time:    3,
+		Memory:  64 * 1024,
+		Threads: 4,
+	}
+}
+
+// Password derives the password of a site and login from the master password. The site, login,
+// counter, policy and parameters all have to be the same to derive the same password again,
+// where the counter can be increased to change the password of a site.
+func Password(master []byte, config *Config) (string, error) {
+	if len(master) == 0 {
+		return "", errors.New("The master password cannot be empty")
+	}
+	if config.Site == "" {
+		return "", errors.New("The site of a derived password cannot be empty")
+	}
+
+	params := config.Params
+	if params == nil {
+		params = DefaultParams()
+	}
+	if params.Time == 0 || params.Threads == 0 || params.Memory < 8*uint32(params.Threads) {
+		return "", errors.New("The key derivation parameters are invalid, verify the configuration")
+	}
+
+	policy, err := newPolicy(config.Policy)
+	if err != nil {
+		return "", err
+	}
+
+	keyLength := 32 + 8*uint32(policy.maxLength)
+	key := argon2.IDKey(master, salt(config), params.Time, params.Memory, params.Threads, keyLength)
+	return policy.password(&entropy{value: new(big.Int).SetBytes(key)})
+}
+
+// policy is the character set, classes and lengths of derived passwords.
+type policy struct {
+	charSet   []rune
+	classes   []generator.CharClass
+	minLength int
+	maxLength int
+}
+
+func newPolicy(config *generator.Config) (*policy, error) {
+	if config == nil {
+		config = generator.DefaultConfig()
+	}
+	if config.Pronounceable {
+		return nil, errors.New("Derived passwords cannot be pronounceable")
+	}
+
+	// The generator validates the policy and resolves its character set and classes
+	configCopy := *config
+	configCopy.Rand = nil
+	gen, err := generator.New(&configCopy)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &policy{
+		charSet:   gen.CharSet(),
+		classes:   gen.Classes(),
+		minLength: gen.MinLength(),
+		maxLength: gen.MaxLength(),
+	}
+	if len(config.CharSet) == 0 && len(config.Classes) == 0 {
+		p.charSet = asciiRunes(p.charSet)
+		for i := range p.classes {
+			p.classes[i].Runes = asciiRunes(p.classes[i].Runes)
+			if p.classes[i].Min > 0 && len(p.classes[i].Runes) == 0 {
+				return nil, errors.Errorf("The character class %s has no ASCII characters for a derived password", p.classes[i].Name)
+			}
+		}
+	}
+	return p, nil
+}
+
+// password maps the entropy onto a password of the policy, see the package documentation.
+func (p *policy) password(e *entropy) (string, error) {
+	length := p.minLength + e.choose(p.maxLength-p.minLength+1)
+
+	var required []rune
+	classCounts := make([]int, len(p.classes))
+	for i, class := range p.classes {
+		for j := 0; j < class.Min; j++ {
+			required = append(required, class.Runes[e.choose(len(class.Runes))])
+		}
+		classCounts[i] = class.Min
+	}
+
+	password := make([]rune, 0, length)
+	for len(password)+len(required) < length {
+		available := p.availableRunes(classCounts)
+		if len(available) == 0 {
+			return "", errors.New("There are no characters left for the derived password, verify the configuration")
+		}
+
+		r := available[e.choose(len(available))]
+		password = append(password, r)
+		for i, class := range p.classes {
+			if containsRune(class.Runes, r) {
+				classCounts[i]++
+			}
+		}
+	}
+
+	for _, r := range required {
+		position := e.choose(len(password) + 1)
+		password = append(password, 0)
+		copy(password[position+1:], password[position:])
+		password[position] = r
+	}
+	return string(password), nil
+}
+
+// availableRunes returns the characters of the character set, without the characters of the
+// classes that have reached their maximum.
+func (p *policy) availableRunes(classCounts []int) []rune {
+	available := make([]rune, 0, len(p.charSet))
+	for _, r := range p.charSet {
+		full := false
+		for i, class := range p.classes {
+			if class.Max > 0 && classCounts[i] >= class.Max && containsRune(class.Runes, r) {
+				full = true
+				break
+			}
+		}
+		if !full {
+			available = append(available, r)
+		}
+	}
+	return available
+}
+
+// entropy is the derived key as an integer, which the choices of the password are taken from.
+type entropy struct {
+	value *big.Int
+}
+
+// choose returns a number in [0,n), which is the remainder of dividing the entropy by n.
+func (e *entropy) choose(n int) int {
+	remainder := new(big.Int)
+	e.value.QuoRem(e.value, big.NewInt(int64(n)), remainder)
+	return int(remainder.Int64())
+}
+
+// salt encodes the inputs with their lengths, so that different inputs never share a salt.
+func salt(config *Config) []byte {
+	var salt []byte
+	salt = appendField(salt, []byte(saltPrefix))
+	salt = appendField(salt, []byte(config.Site))
+	salt = appendField(salt, []byte(config.Login))
+
+	var counter [4]byte
+	binary.BigEndian.PutUint32(counter[:], config.Counter)
+	return append(salt, counter[:]...)
+}
+
+func appendField(buffer []byte, field []byte) []byte {
+	var length [4]byte
+	binary.BigEndian.PutUint32(length[:], uint32(len(field)))
+	buffer = append(buffer, length[:]...)
+	return append(buffer, field...)
+}
+
+func asciiRunes(runes []rune) []rune {
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if r <= unicode.MaxASCII {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func containsRune(runes []rune, r rune) bool {
+	for _, candidate := range runes {
+		if candidate == r {
+			return true
+		}
+	}
+	return false
+}
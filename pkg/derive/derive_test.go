@@ -0,0 +1,106 @@
+package derive_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/derive"
+	"github.com/whinarn/strongpass/pkg/generator"
+)
+
+// testVector is a published test vector of the derivation, where a missing policy or parameters
+// mean the default generator configuration and the default parameters.
+type testVector struct {
+	Master  string `json:"master"`
+	Site    string `json:"site"`
+	Login   string `json:"login"`
+	Counter uint32 `json:"counter"`
+	Policy  *struct {
+		MinLength int    `json:"minLength"`
+		MaxLength int    `json:"maxLength"`
+		CharSet   string `json:"charset"`
+	} `json:"policy"`
+	Params   *derive.Params `json:"params"`
+	Password string         `json:"password"`
+}
+
+func TestPasswordShouldMatchTestVectors(t *testing.T) {
+	data, err := ioutil.ReadFile("testdata/vectors.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	var vectors []testVector
+	if !assert.NoError(t, json.Unmarshal(data, &vectors)) {
+		return
+	}
+
+	for _, vector := range vectors {
+		config := &derive.Config{
+			Site:    vector.Site,
+			Login:   vector.Login,
+			Counter: vector.Counter,
+			Params:  vector.Params,
+		}
+		if vector.Policy != nil {
+			config.Policy = generator.DefaultConfig()
+			config.Policy.MinLength = vector.Policy.MinLength
+			config.Policy.MaxLength = vector.Policy.MaxLength
+			config.Policy.CharSet = []rune(vector.Policy.CharSet)
+		}
+
+		password, err := derive.Password([]byte(vector.Master), config)
+		assert.NoError(t, err, vector.Site)
+		assert.Equal(t, vector.Password, password, vector.Site)
+	}
+}
+
+func TestPasswordShouldSatisfyPolicy(t *testing.T) {
+	policy := generator.DefaultConfig()
+	policy.MinLength = 12
+	policy.MaxLength = 12
+	policy.MinDigits = 3
+	config := &derive.Config{
+		Site:   "example.com",
+		Policy: policy,
+		Params: &derive.Params{Time: 1, Memory: 64, Threads: 1},
+	}
+
+	password, err := derive.Password([]byte("master"), config)
+	assert.NoError(t, err)
+	assert.Empty(t, policy.Check(password))
+	assert.Nil(t, policy.Rand)
+}
+
+func TestPasswordDefaultShouldBeASCII(t *testing.T) {
+	params := &derive.Params{Time: 1, Memory: 64, Threads: 1}
+	for counter := uint32(0); counter < 20; counter++ {
+		password, err := derive.Password([]byte("master"), &derive.Config{Site: "example.com", Counter: counter, Params: params})
+		assert.NoError(t, err)
+		for _, r := range password {
+			assert.True(t, r <= unicode.MaxASCII, password)
+		}
+	}
+}
+
+func TestPasswordShouldFail(t *testing.T) {
+	params := &derive.Params{Time: 1, Memory: 64, Threads: 1}
+	_, err := derive.Password(nil, &derive.Config{Site: "example.com", Params: params})
+	assert.Error(t, err)
+	_, err = derive.Password([]byte("master"), &derive.Config{Params: params})
+	assert.Error(t, err)
+	_, err = derive.Password([]byte("master"), &derive.Config{Site: "example.com", Params: &derive.Params{Time: 0, Memory: 64, Threads: 1}})
+	assert.Error(t, err)
+
+	policy := generator.DefaultConfig()
+	policy.MinLength = 0
+	_, err = derive.Password([]byte("master"), &derive.Config{Site: "example.com", Policy: policy, Params: params})
+	assert.Error(t, err)
+
+	policy = generator.DefaultConfig()
+	policy.Pronounceable = true
+	_, err = derive.Password([]byte("master"), &derive.Config{Site: "example.com", Policy: policy, Params: params})
+	assert.Error(t, err)
+}
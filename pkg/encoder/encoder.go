@@ -0,0 +1,193 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package encoder encodes random bytes as text, with the common encodings of secrets such as
+// hexadecimal, base32, base64, base58 and Z85.
+package encoder
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+const (
+	hexAlphabet       = "0123456789abcdef"
+	base32Alphabet    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	base58Alphabet    = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	base64Alphabet    = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"
+	base64URLAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	z85Alphabet       = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ.-:+=^!/*?&<>()[]{}@%$#"
+)
+
+// Encoding encodes bytes as text.
+type Encoding struct {
+	// Name is the name that the encoding is looked up by.
+	Name string
+	// Alphabet has the characters of the encoding, without any padding character.
+	Alphabet string
+	// Padded is true if the encoding pads its output to a multiple of a block size.
+	Padded bool
+
+	encode func(data []byte) (string, error)
+}
+
+var encodings = map[string]*Encoding{
+	"hex":           {Name: "hex", Alphabet: hexAlphabet, encode: encodeHex},
+	"HEX":           {Name: "HEX", Alphabet: strings.ToUpper(hexAlphabet), encode: encodeUpperHex},
+	"base32":        {Name: "base32", Alphabet: base32Alphabet, Padded: true, encode: encodeString(base32.StdEncoding)},
+	"base32-raw":    {Name: "base32-raw", Alphabet: base32Alphabet, encode: encodeString(base32.StdEncoding.WithPadding(base32.NoPadding))},
+	"crockford":     {Name: "crockford", Alphabet: crockfordAlphabet, encode: encodeString(base32.NewEncoding(crockfordAlphabet).WithPadding(base32.NoPadding))},
+	"base58":        {Name: "base58", Alphabet: base58Alphabet, encode: encodeBase58},
+	"base64":        {Name: "base64", Alphabet: base64Alphabet, Padded: true, encode: encodeString(base64.StdEncoding)},
+	"base64-raw":    {Name: "base64-raw", Alphabet: base64Alphabet, encode: encodeString(base64.RawStdEncoding)},
+	"base64url":     {Name: "base64url", Alphabet: base64URLAlphabet, Padded: true, encode: encodeString(base64.URLEncoding)},
+	"base64url-raw": {Name: "base64url-raw", Alphabet: base64URLAlphabet, encode: encodeString(base64.RawURLEncoding)},
+	"z85":           {Name: "z85", Alphabet: z85Alphabet, encode: encodeZ85},
+}
+
+// Lookup returns the encoding with the specified name.
+func Lookup(name string) (*Encoding, error) {
+	encoding, ok := encodings[name]
+	if !ok {
+		return nil, errors.Errorf("Unknown encoding %q, expected one of %s", name, strings.Join(Names(), ", "))
+	}
+	return encoding, nil
+}
+
+// Names returns the sorted names of all encodings.
+func Names() []string {
+	names := make([]string, 0, len(encodings))
+	for name := range encodings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Encode encodes data. Z85 can only encode data with a length that is a multiple of 4.
+func (e *Encoding) Encode(data []byte) (string, error) {
+	return e.encode(data)
+}
+
+// GenerateBytes returns n random bytes in the encoding, or an error if the random source fails.
+func (e *Encoding) GenerateBytes(n int) (string, error) {
+	if n <= 0 {
+		return "", errors.New("The number of bytes must be over zero")
+	}
+
+	data := make([]byte, n)
+	if _, err := rand.Read(data); err != nil {
+		return "", errors.Wrap(err, "Failed to generate random bytes")
+	}
+	return e.Encode(data)
+}
+
+// GenerateChars returns n random characters of the alphabet of the encoding, or an error if the
+// random source fails. Every character is drawn uniformly, so the text has the full entropy of its
+// length, but it does not always decode to whole bytes. Encodings with padding are not supported.
+func (e *Encoding) GenerateChars(n int) (string, error) {
+	if n <= 0 {
+		return "", errors.New("The number of characters must be over zero")
+	}
+	if e.Padded {
+		return "", errors.Errorf("The encoding %s is padded, so its length can only be set in bytes", e.Name)
+	}
+
+	text := make([]byte, n)
+	for i := range text {
+		index, err := rand.IntnE(len(e.Alphabet))
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to generate random characters")
+		}
+		text[i] = e.Alphabet[index]
+	}
+	return string(text), nil
+}
+
+func encodeHex(data []byte) (string, error) {
+	return hex.EncodeToString(data), nil
+}
+
+func encodeUpperHex(data []byte) (string, error) {
+	return strings.ToUpper(hex.EncodeToString(data)), nil
+}
+
+func encodeString(encoding interface{ EncodeToString([]byte) string }) func([]byte) (string, error) {
+	return func(data []byte) (string, error) {
+		return encoding.EncodeToString(data), nil
+	}
+}
+
+// encodeBase58 encodes data as a big-endian number in base 58 with the Bitcoin alphabet, where
+// every leading zero byte is encoded as a leading 1.
+func encodeBase58(data []byte) (string, error) {
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	var digits []byte
+	value := new(big.Int).SetBytes(data)
+	base := big.NewInt(int64(len(base58Alphabet)))
+	remainder := new(big.Int)
+	for value.Sign() > 0 {
+		value.DivMod(value, base, remainder)
+		digits = append(digits, base58Alphabet[remainder.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		digits = append(digits, base58Alphabet[0])
+	}
+
+	for i, j := 0, len(digits)-1; i < j; i, j = i+1, j-1 {
+		digits[i], digits[j] = digits[j], digits[i]
+	}
+	return string(digits), nil
+}
+
+// encodeZ85 encodes data with the Z85 encoding of ZeroMQ, as 5 characters for every 4 bytes.
+func encodeZ85(data []byte) (string, error) {
+	if len(data)%4 != 0 {
+		return "", errors.Errorf("Z85 can only encode a multiple of 4 bytes, not %d bytes", len(data))
+	}
+
+	text := make([]byte, 0, len(data)/4*5)
+	for i := 0; i < len(data); i += 4 {
+		value := uint32(data[i])<<24 | uint32(data[i+1])<<16 | uint32(data[i+2])<<8 | uint32(data[i+3])
+		var block [5]byte
+		for j := len(block) - 1; j >= 0; j-- {
+			block[j] = z85Alphabet[value%85]
+			value /= 85
+		}
+		text = append(text, block[:]...)
+	}
+	return string(text), nil
+}
@@ -0,0 +1,92 @@
+package encoder_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/encoder"
+)
+
+func TestEncodeShouldSucceed(t *testing.T) {
+	data := []byte{0x00, 0x86, 0x4f, 0xd2, 0x6f, 0xb5, 0x59, 0xf7, 0x5b}
+	helloWorld := []byte{0x86, 0x4f, 0xd2, 0x6f, 0xb5, 0x59, 0xf7, 0x5b}
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{"hex", data, "00864fd26fb559f75b"},
+		{"HEX", data, "00864FD26FB559F75B"},
+		{"base32", []byte("foobar"), "MZXW6YTBOI======"},
+		{"base32-raw", []byte("foobar"), "MZXW6YTBOI"},
+		{"crockford", []byte("foobar"), "CSQPYRK1E8"},
+		{"base58", []byte("hello world"), "StV1DL6CwTryKyV"},
+		{"base58", []byte{0x00, 0x00, 0x28, 0x7f, 0xb4, 0xcd}, "11233QC4"},
+		{"base64", []byte("foob"), "Zm9vYg=="},
+		{"base64-raw", []byte("foob"), "Zm9vYg"},
+		{"base64url", []byte{0xfb, 0xff}, "-_8="},
+		{"base64url-raw", []byte{0xfb, 0xff}, "-_8"},
+		// The test vector of the Z85 specification
+		{"z85", helloWorld, "HelloWorld"},
+	}
+
+	for _, test := range tests {
+		encoding, err := encoder.Lookup(test.name)
+		assert.NoError(t, err, test.name)
+		text, err := encoding.Encode(test.data)
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, text, test.name)
+	}
+}
+
+func TestEncodeShouldFail(t *testing.T) {
+	_, err := encoder.Lookup("base36")
+	assert.Error(t, err)
+
+	encoding, err := encoder.Lookup("z85")
+	assert.NoError(t, err)
+	_, err = encoding.Encode([]byte{1, 2, 3})
+	assert.Error(t, err)
+}
+
+func TestGenerateBytesShouldSucceed(t *testing.T) {
+	lengths := map[string]int{
+		"hex":        64,
+		"base32":     56,
+		"base32-raw": 52,
+		"base64":     44,
+		"base64-raw": 43,
+		"z85":        40,
+	}
+	for name, length := range lengths {
+		encoding, err := encoder.Lookup(name)
+		assert.NoError(t, err, name)
+		text, err := encoding.GenerateBytes(32)
+		assert.NoError(t, err, name)
+		assert.Len(t, text, length, name)
+	}
+}
+
+func TestGenerateCharsShouldSucceed(t *testing.T) {
+	for _, name := range []string{"hex", "crockford", "base58", "base64url-raw", "z85"} {
+		encoding, err := encoder.Lookup(name)
+		assert.NoError(t, err, name)
+		text, err := encoding.GenerateChars(25)
+		assert.NoError(t, err, name)
+		assert.Len(t, text, 25, name)
+		assert.Empty(t, strings.Trim(text, encoding.Alphabet), name)
+	}
+}
+
+func TestGenerateShouldFail(t *testing.T) {
+	encoding, _ := encoder.Lookup("base64")
+	_, err := encoding.GenerateChars(20)
+	assert.Error(t, err)
+	_, err = encoding.GenerateBytes(0)
+	assert.Error(t, err)
+
+	encoding, _ = encoder.Lookup("z85")
+	_, err = encoding.GenerateBytes(30)
+	assert.Error(t, err)
+}
@@ -0,0 +1,117 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generator
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// GeneratePasswords generates n passwords, or returns an error if the random source fails.
+func (gen *Generator) GeneratePasswords(n int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	passwords := make([]string, n)
+	for i := range passwords {
+		password, err := gen.GeneratePasswordE()
+		if err != nil {
+			return nil, err
+		}
+		passwords[i] = password
+	}
+	return passwords, nil
+}
+
+// GeneratePasswordsParallel generates n passwords with a pool of workers, which is faster than
+// GeneratePasswords on multiple cores. If workers is zero or negative, there is one worker per
+// processor that Go may use.
+func (gen *Generator) GeneratePasswordsParallel(n, workers int) ([]string, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > n {
+		workers = n
+	}
+
+	// Each worker fills its own part of the passwords, so only the errors need synchronization
+	passwords := make([]string, n)
+	errs := make(chan error, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for worker := 0; worker < workers; worker++ {
+		start := worker * n / workers
+		end := (worker + 1) * n / workers
+		go func(part []string) {
+			defer wg.Done()
+			for i := range part {
+				password, err := gen.GeneratePasswordE()
+				if err != nil {
+					errs <- err
+					return
+				}
+				part[i] = password
+			}
+		}(passwords[start:end])
+	}
+	wg.Wait()
+	close(errs)
+
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	return passwords, nil
+}
+
+// Passwords returns a channel of passwords that are generated as they are received, and a
+// channel that receives an error if the random source fails. Both channels are closed once the
+// context is done or an error occurs, so receivers should cancel the context when they are finished.
+func (gen *Generator) Passwords(ctx context.Context) (<-chan string, <-chan error) {
+	passwords := make(chan string)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(passwords)
+		for {
+			password, err := gen.GeneratePasswordE()
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			select {
+			case passwords <- password:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return passwords, errs
+}
@@ -0,0 +1,121 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generator
+
+import (
+	"fmt"
+)
+
+// Rule is a rule of a password policy.
+type Rule string
+
+const (
+	// MinLengthRule requires passwords to have at least the minimum length.
+	MinLengthRule Rule = "minlength"
+	// MaxLengthRule requires passwords to have at most the maximum length.
+	MaxLengthRule Rule = "maxlength"
+	// MinClassRule requires passwords to have at least the minimum number of characters of a class.
+	MinClassRule Rule = "minclass"
+	// MaxClassRule requires passwords to have at most the maximum number of characters of a class.
+	MaxClassRule Rule = "maxclass"
+	// CharSetRule requires passwords to only have characters from the character set.
+	CharSetRule Rule = "charset"
+)
+
+// Violation is a violation of a rule by a password.
+type Violation struct {
+	Rule Rule
+	// Class is the name of the character class for class rules.
+	Class   string
+	Message string
+}
+
+// Check checks a password against the configuration, the same policy that is used to generate
+// passwords. The length is counted in characters, and the characters of each class are the same
+// as the ones used for generating. Negative minimums and maximums are treated as zero. It returns the rules
+// that the password violates, or nil if the password satisfies the policy.
+func (config *Config) Check(password string) []Violation {
+	passwordRunes := []rune(password)
+
+	var violations []Violation
+	if len(passwordRunes) < config.MinLength {
+		violations = append(violations, Violation{
+			Rule:    MinLengthRule,
+			Message: fmt.Sprintf("The password is shorter than the minimum length of %d characters", config.MinLength),
+		})
+	}
+	if config.MaxLength > 0 && len(passwordRunes) > config.MaxLength {
+		violations = append(violations, Violation{
+			Rule:    MaxLengthRule,
+			Message: fmt.Sprintf("The password is longer than the maximum length of %d characters", config.MaxLength),
+		})
+	}
+
+	for _, class := range config.charClasses() {
+		count := countRunesIn(passwordRunes, newRuneSet(class.Runes))
+		if count < class.Min {
+			violations = append(violations, Violation{
+				Rule:    MinClassRule,
+				Class:   class.Name,
+				Message: fmt.Sprintf("The password has %d characters of class %s, but at least %d are required", count, class.Name, class.Min),
+			})
+		}
+		if class.Max > 0 && count > class.Max {
+			violations = append(violations, Violation{
+				Rule:    MaxClassRule,
+				Class:   class.Name,
+				Message: fmt.Sprintf("The password has %d characters of class %s, but at most %d are allowed", count, class.Name, class.Max),
+			})
+		}
+	}
+
+	charSet := newRuneSet(config.buildCharSet())
+	disallowed := 0
+	for _, r := range passwordRunes {
+		if !charSet.contains(r) {
+			disallowed++
+		}
+	}
+	if disallowed > 0 {
+		// Only the number of characters is reported, since messages are often logged and the
+		// characters themselves would reveal part of the password
+		violations = append(violations, Violation{
+			Rule:    CharSetRule,
+			Message: fmt.Sprintf("The password has %d characters that are not allowed", disallowed),
+		})
+	}
+
+	return violations
+}
+
+func countRunesIn(runes []rune, set runeSet) int {
+	count := 0
+	for _, r := range runes {
+		if set.contains(r) {
+			count++
+		}
+	}
+	return count
+}
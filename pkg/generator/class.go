@@ -0,0 +1,179 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generator
+
+import (
+	"github.com/pkg/errors"
+)
+
+// Names of the built-in character classes.
+const (
+	LowerCaseLettersClassName = "lowercase"
+	UpperCaseLettersClassName = "uppercase"
+	DigitsClassName           = "digits"
+	SpecialsClassName         = "specials"
+)
+
+// CharClass is a named class of characters, with bounds on how many of them a password has.
+type CharClass struct {
+	Name  string
+	Runes []rune
+	// Min is the minimum number of characters of the class in a password.
+	Min int
+	// Max is the maximum number of characters of the class in a password, or zero for no maximum.
+	Max int
+}
+
+// LowerCaseLetters returns the built-in class of lower-case letters.
+func LowerCaseLetters(min, max int) CharClass {
+	return newBuiltInClass(LowerCaseLettersClassName, lowerCaseLetterRunes, min, max)
+}
+
+// UpperCaseLetters returns the built-in class of upper-case letters.
+func UpperCaseLetters(min, max int) CharClass {
+	return newBuiltInClass(UpperCaseLettersClassName, upperCaseLetterRunes, min, max)
+}
+
+// Digits returns the built-in class of digits.
+func Digits(min, max int) CharClass {
+	return newBuiltInClass(DigitsClassName, digitRunes, min, max)
+}
+
+// Specials returns the built-in class of special symbols.
+func Specials(min, max int) CharClass {
+	return newBuiltInClass(SpecialsClassName, specialRunes, min, max)
+}
+
+func newBuiltInClass(name string, runes []rune, min, max int) CharClass {
+	return CharClass{
+		Name:  name,
+		Runes: copyRunes(runes),
+		Min:   min,
+		Max:   max,
+	}
+}
+
+// charClasses returns a normalized copy of the character classes of the configuration, where each
+// class only has the characters that are also in the character set. Without any configured classes,
+// the built-in classes are used with the legacy minimums and maximums.
+func (config *Config) charClasses() []CharClass {
+	var classes []CharClass
+	if len(config.Classes) > 0 {
+		for _, class := range config.Classes {
+			class.Runes = copyRunes(class.Runes)
+			classes = append(classes, class)
+		}
+	} else {
+		classes = []CharClass{
+			LowerCaseLetters(config.MinLowerCaseLetters, config.MaxLowerCaseLetters),
+			UpperCaseLetters(config.MinUpperCaseLetters, config.MaxUpperCaseLetters),
+			Digits(config.MinDigits, config.MaxDigits),
+			Specials(config.MinSpecials, config.MaxSpecials),
+		}
+	}
+
+	charSet := newRuneSet(config.buildCharSet())
+	for i := range classes {
+		classes[i].Runes = intersectRunes(classes[i].Runes, charSet)
+		if classes[i].Min < 0 {
+			classes[i].Min = 0
+		}
+		if classes[i].Max < 0 {
+			classes[i].Max = 0
+		}
+	}
+	return classes
+}
+
+func validateClasses(classes []CharClass) error {
+	for i, class := range classes {
+		if class.Max > 0 && class.Max < class.Min {
+			return errors.Errorf("The maximum number of characters of class %s cannot be lower than the minimum", class.Name)
+		}
+		if class.Min > 0 && len(class.Runes) == 0 {
+			return errors.Errorf("The character class %s has a minimum but none of its characters are available, verify the configuration", class.Name)
+		}
+
+		classSet := newRuneSet(class.Runes)
+		for _, other := range classes[i+1:] {
+			for _, r := range other.Runes {
+				if classSet.contains(r) {
+					return errors.Errorf("The character classes %s and %s overlap, verify the configuration", class.Name, other.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// maxPasswordLength returns the maximum length of passwords that satisfy the maximums of the
+// classes, or -1 if there is no such limit.
+func maxPasswordLength(classes []CharClass, charSet []rune) int {
+	classIndexes := classIndexesOf(classes)
+	for _, r := range charSet {
+		classIndex, ok := classIndexes[r]
+		if !ok || classes[classIndex].Max == 0 {
+			// Characters without a class or a maximum are unlimited
+			return -1
+		}
+	}
+
+	length := 0
+	for _, class := range classes {
+		if len(class.Runes) > 0 {
+			length += class.Max
+		}
+	}
+	return length
+}
+
+func intersectRunes(runes []rune, set runeSet) []rune {
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if set.contains(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func classIndexesOf(classes []CharClass) map[rune]int {
+	classIndexes := make(map[rune]int)
+	for i, class := range classes {
+		for _, r := range class.Runes {
+			classIndexes[r] = i
+		}
+	}
+	return classIndexes
+}
+
+func copyRunes(runes []rune) []rune {
+	if runes == nil {
+		return nil
+	}
+	result := make([]rune, len(runes))
+	copy(result, runes)
+	return result
+}
@@ -0,0 +1,159 @@
+package generator_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/generator"
+)
+
+func TestNewWithClassesShouldSucceed(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.LowerCaseLetters(1, 0),
+			generator.Digits(0, 3),
+			{Name: "symbols", Runes: []rune("!@#$"), Min: 2},
+		},
+		MinLength: 10,
+		MaxLength: 16,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.NoError(t, err)
+	assert.NotNil(t, generator)
+
+	for i := 0; i < 200; i++ {
+		password := generator.GeneratePassword()
+		assert.GreaterOrEqual(t, len(password), 10)
+		assert.LessOrEqual(t, len(password), 16)
+
+		symbols, digits := 0, 0
+		for _, r := range password {
+			switch {
+			case strings.ContainsRune("!@#$", r):
+				symbols++
+			case r >= '0' && r <= '9':
+				digits++
+			default:
+				assert.True(t, r >= 'a' && r <= 'z', password)
+			}
+		}
+		assert.GreaterOrEqual(t, symbols, 2, password)
+		assert.LessOrEqual(t, digits, 3, password)
+	}
+}
+
+func TestNewWithLargeClassShouldSucceed(t *testing.T) {
+	var letters []rune
+	for r := rune(0x4e00); r < 0x4e00+20000; r++ {
+		letters = append(letters, r)
+	}
+	generatorConfig := generator.Config{
+		Classes:   []generator.CharClass{{Name: "letters", Runes: letters, Min: 1}},
+		MinLength: 10,
+		MaxLength: 10,
+	}
+	generator, err := generator.New(&generatorConfig)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	password := generator.GeneratePassword()
+	assert.Len(t, []rune(password), 10)
+	assert.Empty(t, generatorConfig.Check(password))
+}
+
+func TestNewWithOverlappingClassesShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.Specials(1, 0),
+			{Name: "symbols", Runes: []rune("!@#$"), Min: 1},
+		},
+		MinLength: 10,
+		MaxLength: 10,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "overlap")
+}
+
+func TestNewWithClassMaximumBelowMinimumShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.LowerCaseLetters(0, 0),
+			generator.Digits(3, 2),
+		},
+		MinLength: 10,
+		MaxLength: 10,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "cannot be lower than the minimum")
+}
+
+func TestNewWithClassMaximumsBelowMinimumLengthShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.LowerCaseLetters(0, 4),
+			generator.Digits(0, 4),
+		},
+		MinLength: 10,
+		MaxLength: 10,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "higher than the classes allow")
+}
+
+func TestGeneratePasswordWithClassMaximumsShouldLimitLength(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.LowerCaseLetters(0, 4),
+			generator.Digits(0, 4),
+		},
+		MinLength: 6,
+		MaxLength: 12,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		password := generator.GeneratePassword()
+		assert.GreaterOrEqual(t, len(password), 6)
+		assert.LessOrEqual(t, len(password), 8)
+	}
+}
+
+func TestGeneratorKeyspaceWithClassMaximumShouldSucceed(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.LowerCaseLetters(0, 0),
+			generator.Digits(0, 1),
+		},
+		MinLength: 2,
+		MaxLength: 2,
+	}
+	generator, _ := generator.New(&generatorConfig)
+
+	// Everything except two digits
+	assert.Equal(t, big.NewInt(36*36-10*10), generator.Keyspace())
+}
+
+func TestConfigCheckClassMaximumShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.LowerCaseLetters(0, 0),
+			generator.Digits(0, 3),
+		},
+		MinLength: 4,
+		MaxLength: 10,
+	}
+	violations := generatorConfig.Check("abc12345")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, generator.MaxClassRule, violations[0].Rule)
+	assert.Equal(t, "digits", violations[0].Class)
+}
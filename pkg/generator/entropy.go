@@ -0,0 +1,46 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generator
+
+import (
+	"math/big"
+
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+// Keyspace returns the number of distinct passwords that satisfy the configured policy.
+// Every length between the minimum and maximum length is counted, and the per-class
+// minimums and maximums are accounted for exactly using the inclusion-exclusion principle.
+// Only characters in the character set are counted. Pronounceable passwords are counted
+// by their structure instead, which is a far smaller keyspace for the same length.
+func (gen *Generator) Keyspace() *big.Int {
+	return gen.sampler.count()
+}
+
+// Entropy returns the entropy of the configured policy in bits, which is the
+// base-2 logarithm of the keyspace.
+func (gen *Generator) Entropy() float64 {
+	return rand.Entropy(gen.Keyspace())
+}
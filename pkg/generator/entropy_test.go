@@ -0,0 +1,95 @@
+package generator_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/generator"
+)
+
+func TestGeneratorKeyspaceWithoutMinimumsShouldSucceed(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowLowerCaseLetters: true,
+		MinLength:             3,
+		MaxLength:             3,
+	}
+	generator, _ := generator.New(&generatorConfig)
+	assert.Equal(t, big.NewInt(26*26*26), generator.Keyspace())
+	assert.InDelta(t, math.Log2(26*26*26), generator.Entropy(), 1e-9)
+}
+
+func TestGeneratorKeyspaceWithMinimumsShouldSucceed(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowLowerCaseLetters: true,
+		AllowDigits:           true,
+		MinLength:             2,
+		MaxLength:             2,
+		MinLowerCaseLetters:   1,
+		MinDigits:             1,
+	}
+	generator, _ := generator.New(&generatorConfig)
+
+	// One lower-case letter and one digit, in either order
+	assert.Equal(t, big.NewInt(2*26*10), generator.Keyspace())
+}
+
+func TestGeneratorKeyspaceWithLengthRangeShouldSucceed(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowLowerCaseLetters: true,
+		AllowUpperCaseLetters: true,
+		MinLength:             1,
+		MaxLength:             2,
+		MinLowerCaseLetters:   1,
+	}
+	generator, _ := generator.New(&generatorConfig)
+
+	// Length 1: 26, length 2: 52^2 - 26^2
+	assert.Equal(t, big.NewInt(26+52*52-26*26), generator.Keyspace())
+}
+
+func TestGeneratorKeyspaceShouldMatchBruteForce(t *testing.T) {
+	generatorConfig := generator.Config{
+		CharSet:             []rune("abcAB12#"),
+		MinLength:           3,
+		MaxLength:           4,
+		MinLowerCaseLetters: 1,
+		MinUpperCaseLetters: 1,
+		MinDigits:           1,
+	}
+	generator, _ := generator.New(&generatorConfig)
+
+	alphabet := []rune("abcAB12#")
+	expected := 0
+	for length := 3; length <= 4; length++ {
+		combinations := 1
+		for i := 0; i < length; i++ {
+			combinations *= len(alphabet)
+		}
+		for index := 0; index < combinations; index++ {
+			var lower, upper, digits int
+			for i, value := 0, index; i < length; i, value = i+1, value/len(alphabet) {
+				switch r := alphabet[value%len(alphabet)]; {
+				case r >= 'a' && r <= 'z':
+					lower++
+				case r >= 'A' && r <= 'Z':
+					upper++
+				case r >= '0' && r <= '9':
+					digits++
+				}
+			}
+			if lower >= 1 && upper >= 1 && digits >= 1 {
+				expected++
+			}
+		}
+	}
+	assert.Equal(t, big.NewInt(int64(expected)), generator.Keyspace())
+}
+
+func TestGeneratorEntropyDefaultShouldSucceed(t *testing.T) {
+	generator, _ := generator.New(nil)
+	entropy := generator.Entropy()
+	assert.Greater(t, entropy, 120.0)
+	assert.Less(t, entropy, 26*math.Log2(96))
+}
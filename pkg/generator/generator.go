@@ -25,6 +25,8 @@ SOFTWARE.
 package generator
 
 import (
+	"io"
+
 	"github.com/pkg/errors"
 	"github.com/whinarn/strongpass/pkg/rand"
 )
@@ -34,30 +36,47 @@ var (
 	upperCaseLetterRunes = []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ")
 	digitRunes           = []rune("0123456789")
 	specialRunes         = []rune("§½!#¤%&/()[]{}=?+-*\\£$~^.,:;_<>|@")
+
+	// ambiguousRunes are characters that are easily mistaken for each other when read
+	ambiguousRunes = []rune("0Oo1lI|`'\"")
 )
 
-// Generator is a password generator.
+// Generator is a password generator. A Generator is immutable once created, which makes it
+// safe for concurrent use by multiple goroutines, unless its configuration has a Rand reader
+// that is not.
 type Generator struct {
-	charSet             []rune
-	minLength           int
-	maxLength           int
-	minLowerCaseLetters int
-	minUpperCaseLetters int
-	minDigits           int
-	minSpecials         int
-	minShuffleCount     int
-	maxShuffleCount     int
+	charSet       []rune
+	classes       []CharClass
+	minLength     int
+	maxLength     int
+	pronounceable bool
+	sampler       passwordSampler
+	source        *rand.Source
 }
 
 // Config is the password generator configuration.
 type Config struct {
 	CharSet []rune
 
+	// Classes are the character classes with the bounds of how many characters of each class
+	// a password has. If set, the legacy Allow, Min and Max fields below are ignored, and unless
+	// CharSet is set, the character set is all the characters of the classes.
+	Classes []CharClass
+
 	AllowLowerCaseLetters bool
 	AllowUpperCaseLetters bool
 	AllowDigits           bool
 	AllowSpecials         bool
 
+	// Pronounceable generates passwords of alternating consonants and vowels that are easy to read
+	// out loud, followed by the minimum number of digits and special symbols. Exactly the minimum
+	// number of letters are upper-case. These passwords have a much lower entropy per character.
+	Pronounceable bool
+
+	// ExcludeAmbiguous removes visually ambiguous characters, such as 0 and O, from the
+	// character set and from the character classes.
+	ExcludeAmbiguous bool
+
 	MinLength           int
 	MaxLength           int
 	MinLowerCaseLetters int
@@ -65,7 +84,22 @@ type Config struct {
 	MinDigits           int
 	MinSpecials         int
 
+	// The maximums of each class, where zero means no maximum
+	MaxLowerCaseLetters int
+	MaxUpperCaseLetters int
+	MaxDigits           int
+	MaxSpecials         int
+
+	// Rand is the source of random bytes, which defaults to a cryptographically secure source
+	// seeded by the operating system. A deterministic reader, such as a DRBG with a fixed seed,
+	// makes the generated passwords reproducible, which is only meant for tests. The Generator is
+	// only safe for concurrent use if the reader is.
+	Rand io.Reader
+
+	// Deprecated: Passwords are drawn uniformly from all the passwords that satisfy the
+	// configuration, so the shuffle counts no longer have any effect.
 	MinShuffleCount int
+	// Deprecated: See MinShuffleCount.
 	MaxShuffleCount int
 }
 
@@ -79,21 +113,35 @@ func New(config *Config) (*Generator, error) {
 		return nil, err
 	}
 
-	charSet := config.prepareCharSet()
+	charSet := config.buildCharSet()
 	if len(charSet) == 0 {
 		return nil, errors.New("There are no characters available for passwords, verify the configuration")
 	}
 
+	classes := config.charClasses()
+	maxLength := config.MaxLength
+	if classesMaxLength := maxPasswordLength(classes, charSet); classesMaxLength >= 0 && classesMaxLength < maxLength {
+		maxLength = classesMaxLength
+	}
+
+	var sampler passwordSampler
+	if config.Pronounceable {
+		sampler = newPronounceableSampler(config, config.MinLength, maxLength)
+	} else {
+		sampler = newSampler(charSet, classes, config.MinLength, maxLength)
+	}
+	if sampler.count().Sign() == 0 {
+		return nil, errors.New("There are no passwords that satisfy the configuration, verify the configuration")
+	}
+
 	return &Generator{
-		charSet:             charSet,
-		minLength:           config.MinLength,
-		maxLength:           config.MaxLength,
-		minLowerCaseLetters: config.MinLowerCaseLetters,
-		minUpperCaseLetters: config.MinUpperCaseLetters,
-		minDigits:           config.MinDigits,
-		minSpecials:         config.MinSpecials,
-		minShuffleCount:     config.MinShuffleCount,
-		maxShuffleCount:     config.MaxShuffleCount,
+		charSet:       charSet,
+		classes:       classes,
+		minLength:     config.MinLength,
+		maxLength:     maxLength,
+		pronounceable: config.Pronounceable,
+		sampler:       sampler,
+		source:        rand.NewSource(config.Rand),
 	}, nil
 }
 
@@ -110,49 +158,53 @@ func DefaultConfig() *Config {
 		MinUpperCaseLetters:   1,
 		MinDigits:             1,
 		MinSpecials:           1,
-		MinShuffleCount:       4,
-		MaxShuffleCount:       10,
 	}
 }
 
-// GeneratePassword generates a password.
-func (gen *Generator) GeneratePassword() string {
-	length := gen.minLength
-	if gen.maxLength > length {
-		length += rand.Intn((gen.maxLength - length) + 1)
-	}
-
-	passwordChars := make([]rune, 0, length)
-	passwordChars = gen.appendRandomChars(passwordChars, gen.minLowerCaseLetters, lowerCaseLetterRunes)
-	passwordChars = gen.appendRandomChars(passwordChars, gen.minUpperCaseLetters, upperCaseLetterRunes)
-	passwordChars = gen.appendRandomChars(passwordChars, gen.minDigits, digitRunes)
-	passwordChars = gen.appendRandomChars(passwordChars, gen.minSpecials, specialRunes)
-
-	remainingLength := length - len(passwordChars)
-	passwordChars = gen.appendRandomChars(passwordChars, remainingLength, gen.charSet)
+// CharSet returns the characters that passwords are made of.
+func (gen *Generator) CharSet() []rune {
+	return copyRunes(gen.charSet)
+}
 
-	shuffleCount := gen.minShuffleCount + rand.Intn((gen.maxShuffleCount-gen.minShuffleCount)+1)
-	for i := 0; i < shuffleCount; i++ {
-		rand.Shuffle(len(passwordChars), func(i, j int) {
-			passwordChars[i], passwordChars[j] = passwordChars[j], passwordChars[i]
-		})
+// Classes returns the character classes with their bounds, where each class only has the
+// characters that are also in the character set.
+func (gen *Generator) Classes() []CharClass {
+	classes := make([]CharClass, len(gen.classes))
+	for i, class := range gen.classes {
+		class.Runes = copyRunes(class.Runes)
+		classes[i] = class
 	}
+	return classes
+}
+
+// MinLength returns the minimum length of passwords.
+func (gen *Generator) MinLength() int {
+	return gen.minLength
+}
 
-	return string(passwordChars)
+// MaxLength returns the maximum length of passwords, which is lower than the configured
+// maximum length if the maximums of the classes do not allow for longer passwords.
+func (gen *Generator) MaxLength() int {
+	return gen.maxLength
 }
 
-func (gen *Generator) appendRandomChars(buffer []rune, length int, chars []rune) []rune {
-	charsLen := len(chars)
-	if charsLen == 0 {
-		// There is nothing to append
-		return buffer
+// GeneratePassword generates a password. Every password that satisfies the configuration
+// is equally likely to be generated. It panics if the random source fails, see GeneratePasswordE.
+func (gen *Generator) GeneratePassword() string {
+	password, err := gen.GeneratePasswordE()
+	if err != nil {
+		panic(err)
 	}
+	return password
+}
 
-	for i := 0; i < length; i++ {
-		randomIndex := rand.Intn(charsLen)
-		buffer = append(buffer, chars[randomIndex])
+// GeneratePasswordE generates a password like GeneratePassword, or returns an error if the random source fails.
+func (gen *Generator) GeneratePasswordE() (string, error) {
+	password, err := gen.sampler.sample(gen.source)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate a password")
 	}
-	return buffer
+	return string(password), nil
 }
 
 func (config *Config) validate() error {
@@ -162,40 +214,41 @@ func (config *Config) validate() error {
 		return errors.New("The maximum length of a password cannot be lower than the minimum length")
 	}
 
-	if config.MinLowerCaseLetters < 0 {
-		config.MinLowerCaseLetters = 0
-	}
-	if config.MinUpperCaseLetters < 0 {
-		config.MinUpperCaseLetters = 0
-	}
-	if config.MinDigits < 0 {
-		config.MinDigits = 0
-	}
-	if config.MinSpecials < 0 {
-		config.MinSpecials = 0
-	}
-	if config.MinShuffleCount < 1 {
-		// There has to be at least 1 shuffle, otherwise there is no security at all
-		config.MinShuffleCount = 1
-	}
-	if config.MaxShuffleCount < config.MinShuffleCount {
-		config.MaxShuffleCount = config.MinShuffleCount
+	if config.Pronounceable && (len(config.CharSet) > 0 || len(config.Classes) > 0) {
+		return errors.New("Pronounceable passwords cannot have a custom character set or character classes")
 	}
 
-	requiredMinimum := config.MinLowerCaseLetters + config.MinUpperCaseLetters +
-		config.MinDigits + config.MinSpecials
+	classes := config.charClasses()
+	requiredMinimum := 0
+	for _, class := range classes {
+		requiredMinimum += class.Min
+	}
 	if config.MinLength < requiredMinimum {
 		return errors.New("The minimum length of passwords is lower than the required minimum with the configuration")
 	}
 
+	if err := validateClasses(classes); err != nil {
+		return err
+	}
+	if charSet := config.buildCharSet(); len(charSet) > 0 {
+		if maxLength := maxPasswordLength(classes, charSet); maxLength >= 0 && maxLength < config.MinLength {
+			return errors.New("The minimum length of passwords is higher than the classes allow with their maximums")
+		}
+	}
+
 	return nil
 }
 
-func (config *Config) prepareCharSet() []rune {
+func (config *Config) buildCharSet() []rune {
 	var charSet []rune
 	if len(config.CharSet) > 0 {
-		// We have a pre-defined character set
-		charSet = config.CharSet
+		// We have a pre-defined character set, where duplicates would bias the distribution
+		charSet = removeDuplicateRunes(config.CharSet)
+	} else if len(config.Classes) > 0 {
+		// The character set is made up of the classes
+		for _, class := range config.Classes {
+			charSet = append(charSet, class.Runes...)
+		}
 	} else {
 		// We have to create the character set
 		if config.AllowLowerCaseLetters {
@@ -212,15 +265,48 @@ func (config *Config) prepareCharSet() []rune {
 		}
 	}
 
-	config.shuffleCharSet(charSet)
+	if config.ExcludeAmbiguous {
+		charSet = removeRunes(charSet, ambiguousRunes)
+	}
 	return charSet
 }
 
-func (config *Config) shuffleCharSet(charSet []rune) {
-	shuffleCount := config.MinShuffleCount + rand.Intn((config.MaxShuffleCount-config.MinShuffleCount)+1)
-	for i := 0; i < shuffleCount; i++ {
-		rand.Shuffle(len(charSet), func(i, j int) {
-			charSet[i], charSet[j] = charSet[j], charSet[i]
-		})
+// runeSet is a set of runes, which keeps membership checks constant time for large character sets
+// such as \p{L}.
+type runeSet map[rune]struct{}
+
+func newRuneSet(runes []rune) runeSet {
+	set := make(runeSet, len(runes))
+	for _, r := range runes {
+		set[r] = struct{}{}
+	}
+	return set
+}
+
+func (set runeSet) contains(r rune) bool {
+	_, ok := set[r]
+	return ok
+}
+
+func removeRunes(runes []rune, removed []rune) []rune {
+	removedSet := newRuneSet(removed)
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if !removedSet.contains(r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func removeDuplicateRunes(runes []rune) []rune {
+	seen := make(runeSet, len(runes))
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if !seen.contains(r) {
+			seen[r] = struct{}{}
+			result = append(result, r)
+		}
 	}
+	return result
 }
@@ -1,10 +1,15 @@
 package generator_test
 
 import (
+	"bytes"
+	"context"
+	"math"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/whinarn/strongpass/pkg/generator"
+	"github.com/whinarn/strongpass/pkg/rand"
 )
 
 func TestNewWithoutConfigShouldSucceed(t *testing.T) {
@@ -163,3 +168,549 @@ func TestGeneratorGeneratePasswordWithMinMaxLengthShouldSucceed(t *testing.T) {
 		assert.LessOrEqual(t, len(password), 20)
 	}
 }
+
+func TestConfigCheckValidPasswordShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	violations := generatorConfig.Check("aB3#aB3#aB3#aB3#aB3#")
+	assert.Empty(t, violations)
+}
+
+func TestConfigCheckGeneratedPasswordShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generator, _ := generator.New(generatorConfig)
+
+	for i := 0; i < 100; i++ {
+		password := generator.GeneratePassword()
+		assert.Empty(t, generatorConfig.Check(password), password)
+	}
+}
+
+func TestConfigCheckShortPasswordShouldFail(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	violations := generatorConfig.Check("aB3#")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, generator.MinLengthRule, violations[0].Rule)
+	assert.Contains(t, violations[0].Message, "minimum length of 20")
+}
+
+func TestConfigCheckLongPasswordShouldFail(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	violations := generatorConfig.Check("aB3#aB3#aB3#aB3#aB3#aB3#aB3#")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, generator.MaxLengthRule, violations[0].Rule)
+}
+
+func TestConfigCheckMissingClassesShouldFail(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.MinDigits = 2
+	violations := generatorConfig.Check("abcdefghijklmnopqrst3")
+
+	var classes []string
+	for _, violation := range violations {
+		assert.Equal(t, generator.MinClassRule, violation.Rule)
+		classes = append(classes, violation.Class)
+	}
+	assert.Equal(t, []string{"uppercase", "digits", "specials"}, classes)
+}
+
+func TestConfigCheckDisallowedCharactersShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowLowerCaseLetters: true,
+		MinLength:             4,
+		MaxLength:             10,
+	}
+	violations := generatorConfig.Check("abcd12")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, generator.CharSetRule, violations[0].Rule)
+	assert.Contains(t, violations[0].Message, "2 characters")
+	assert.NotContains(t, violations[0].Message, "12")
+}
+
+func TestNewWithMaximumBelowMinimumShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowLowerCaseLetters: true,
+		AllowSpecials:         true,
+		MinLength:             10,
+		MaxLength:             10,
+		MinSpecials:           3,
+		MaxSpecials:           2,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "cannot be lower than the minimum")
+}
+
+func TestNewWithMaximumTotalBelowMinimumLengthShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowDigits:   true,
+		AllowSpecials: true,
+		MinLength:     10,
+		MaxLength:     12,
+		MaxDigits:     4,
+		MaxSpecials:   4,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "higher than the classes allow")
+}
+
+func TestGeneratorGeneratePasswordWithMaximumsShouldSucceed(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowLowerCaseLetters: true,
+		AllowUpperCaseLetters: true,
+		AllowDigits:           true,
+		AllowSpecials:         true,
+		MinLength:             20,
+		MaxLength:             26,
+		MinDigits:             1,
+		MinSpecials:           1,
+		MaxDigits:             2,
+		MaxSpecials:           1,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		password := generator.GeneratePassword()
+		violations := generatorConfig.Check(password)
+		assert.Empty(t, violations, password)
+	}
+}
+
+func TestGeneratorGeneratePasswordWithoutAmbiguousShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.ExcludeAmbiguous = true
+	generatorConfig.MinDigits = 5
+	generator, err := generator.New(generatorConfig)
+	assert.NoError(t, err)
+
+	for i := 0; i < 100; i++ {
+		password := generator.GeneratePassword()
+		assert.NotContains(t, password, "0")
+		assert.NotContains(t, password, "O")
+		assert.NotContains(t, password, "1")
+		assert.NotContains(t, password, "l")
+		assert.NotContains(t, password, "I")
+		assert.NotContains(t, password, "|")
+		assert.Empty(t, generatorConfig.Check(password), password)
+	}
+}
+
+func TestNewWithoutAmbiguousAndOnlyAmbiguousClassShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		Classes: []generator.CharClass{
+			generator.LowerCaseLetters(0, 0),
+			{Name: "lookalikes", Runes: []rune("0O"), Min: 1},
+		},
+		ExcludeAmbiguous: true,
+		MinLength:        10,
+		MaxLength:        10,
+	}
+	generator, err := generator.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "lookalikes")
+}
+
+func TestConfigCheckAmbiguousCharactersShouldFail(t *testing.T) {
+	generatorConfig := generator.Config{
+		AllowLowerCaseLetters: true,
+		AllowDigits:           true,
+		ExcludeAmbiguous:      true,
+		MinLength:             4,
+		MaxLength:             10,
+	}
+	violations := generatorConfig.Check("abc0def")
+	assert.Len(t, violations, 1)
+	assert.Equal(t, generator.CharSetRule, violations[0].Rule)
+}
+
+func TestGeneratorKeyspaceWithDuplicateCharsShouldIgnoreDuplicates(t *testing.T) {
+	generatorConfig := generator.Config{
+		CharSet:   []rune("aaaab"),
+		MinLength: 4,
+		MaxLength: 4,
+	}
+	generator, _ := generator.New(&generatorConfig)
+	assert.Equal(t, int64(16), generator.Keyspace().Int64())
+}
+
+func TestNewWithMinimumOfDisallowedClassShouldFail(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config generator.Config
+		class  string
+	}{
+		{
+			name:   "specials not allowed",
+			config: generator.Config{AllowLowerCaseLetters: true, MinLength: 10, MaxLength: 10, MinSpecials: 1},
+			class:  "specials",
+		},
+		{
+			name:   "digits not allowed",
+			config: generator.Config{AllowLowerCaseLetters: true, MinLength: 10, MaxLength: 10, MinDigits: 1},
+			class:  "digits",
+		},
+		{
+			name:   "upper-case letters not in charset",
+			config: generator.Config{CharSet: []rune("abc123"), MinLength: 10, MaxLength: 10, MinUpperCaseLetters: 1},
+			class:  "uppercase",
+		},
+		{
+			name:   "lower-case letters not in charset",
+			config: generator.Config{CharSet: []rune("ABC123"), MinLength: 10, MaxLength: 10, MinLowerCaseLetters: 1},
+			class:  "lowercase",
+		},
+	}
+
+	for _, testCase := range testCases {
+		generator, err := generator.New(&testCase.config)
+		assert.Error(t, err, testCase.name)
+		assert.Nil(t, generator, testCase.name)
+		if err != nil {
+			assert.Contains(t, err.Error(), testCase.class, testCase.name)
+			assert.Contains(t, err.Error(), "none of its characters are available", testCase.name)
+		}
+	}
+}
+
+func TestGeneratorGeneratePasswordShouldOnlyUseCharSet(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  generator.Config
+		allowed string
+	}{
+		{
+			name: "specials not allowed",
+			config: generator.Config{
+				AllowLowerCaseLetters: true, AllowUpperCaseLetters: true, AllowDigits: true,
+				MinLength: 20, MaxLength: 20, MinLowerCaseLetters: 1, MinUpperCaseLetters: 1, MinDigits: 1,
+			},
+			allowed: "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789",
+		},
+		{
+			name:    "custom charset without classes",
+			config:  generator.Config{CharSet: []rune("abc"), MinLength: 20, MaxLength: 20},
+			allowed: "abc",
+		},
+		{
+			name: "custom charset with part of the classes",
+			config: generator.Config{
+				CharSet: []rune("abcX12#"), MinLength: 20, MaxLength: 20,
+				MinLowerCaseLetters: 2, MinUpperCaseLetters: 1, MinDigits: 2, MinSpecials: 1,
+			},
+			allowed: "abcX12#",
+		},
+		{
+			name: "custom charset without ambiguous characters",
+			config: generator.Config{
+				CharSet: []rune("0O1lIab23"), ExcludeAmbiguous: true, MinLength: 20, MaxLength: 20,
+				MinDigits: 3,
+			},
+			allowed: "ab23",
+		},
+	}
+
+	for _, testCase := range testCases {
+		generator, err := generator.New(&testCase.config)
+		if !assert.NoError(t, err, testCase.name) {
+			continue
+		}
+
+		for i := 0; i < 50; i++ {
+			password := generator.GeneratePassword()
+			for _, r := range password {
+				assert.Contains(t, testCase.allowed, string(r), testCase.name)
+			}
+			assert.Empty(t, testCase.config.Check(password), testCase.name)
+		}
+	}
+}
+
+func TestGeneratePasswordShouldBeUniform(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config generator.Config
+	}{
+		{
+			// Most strings are valid, so passwords are drawn by rejection sampling
+			name: "rejection",
+			config: generator.Config{
+				CharSet:             []rune("abA1"),
+				MinLength:           1,
+				MaxLength:           3,
+				MinLowerCaseLetters: 1,
+				MaxDigits:           1,
+			},
+		},
+		{
+			// Few strings are valid, so passwords are drawn by exact counting
+			name: "exact",
+			config: generator.Config{
+				CharSet:   []rune("abcdefghijklmnopqrstuvwxyz1"),
+				MinLength: 2,
+				MaxLength: 3,
+				MinDigits: 2,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		generatorConfig := testCase.config
+		generator, err := generator.New(&generatorConfig)
+		if !assert.NoError(t, err, testCase.name) {
+			continue
+		}
+
+		// Enumerate every password of the policy by brute force
+		alphabet := generatorConfig.CharSet
+		counts := make(map[string]int)
+		var enumerate func(prefix string)
+		enumerate = func(prefix string) {
+			if len(prefix) >= generatorConfig.MinLength && len(generatorConfig.Check(prefix)) == 0 {
+				counts[prefix] = 0
+			}
+			if len(prefix) < generatorConfig.MaxLength {
+				for _, r := range alphabet {
+					enumerate(prefix + string(r))
+				}
+			}
+		}
+		enumerate("")
+		assert.Equal(t, int64(len(counts)), generator.Keyspace().Int64(), testCase.name)
+
+		const samplesPerPassword = 200
+		sampleCount := samplesPerPassword * len(counts)
+		for i := 0; i < sampleCount; i++ {
+			password := generator.GeneratePassword()
+			if _, ok := counts[password]; !assert.True(t, ok, "unexpected password %q", password) {
+				return
+			}
+			counts[password]++
+		}
+
+		// Pearson's chi-squared test against the uniform distribution
+		chiSquared := 0.0
+		for _, count := range counts {
+			difference := float64(count - samplesPerPassword)
+			chiSquared += difference * difference / samplesPerPassword
+		}
+
+		// The critical value at a significance level of 0.0001, with the Wilson-Hilferty approximation
+		degreesOfFreedom := float64(len(counts) - 1)
+		const z = 3.719
+		critical := degreesOfFreedom * math.Pow(1-2/(9*degreesOfFreedom)+z*math.Sqrt(2/(9*degreesOfFreedom)), 3)
+		assert.Less(t, chiSquared, critical, testCase.name)
+	}
+}
+
+func TestGeneratePasswordWithLongLengthShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.MinLength = 1000
+	generatorConfig.MaxLength = 1000
+	generator, err := generator.New(generatorConfig)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	password := generator.GeneratePassword()
+	assert.Len(t, []rune(password), 1000)
+	assert.Empty(t, generatorConfig.Check(password))
+}
+
+func TestGeneratePasswordWithLargeClassMinimumsShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.MinLength = 128
+	generatorConfig.MaxLength = 160
+	generatorConfig.MinLowerCaseLetters = 30
+	generatorConfig.MinUpperCaseLetters = 30
+	generatorConfig.MinDigits = 30
+	generatorConfig.MinSpecials = 30
+	generatorConfig.MaxDigits = 60
+	generator, err := generator.New(generatorConfig)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	password := generator.GeneratePassword()
+	assert.Empty(t, generatorConfig.Check(password))
+}
+
+func TestGeneratePasswordsShouldSucceed(t *testing.T) {
+	generator, _ := generator.New(nil)
+	passwords, err := generator.GeneratePasswords(10)
+	assert.NoError(t, err)
+	assert.Len(t, passwords, 10)
+	for _, password := range passwords {
+		assert.NotEmpty(t, password)
+	}
+	passwords, err = generator.GeneratePasswords(0)
+	assert.NoError(t, err)
+	assert.Empty(t, passwords)
+}
+
+func TestPasswordsShouldStopWhenCancelled(t *testing.T) {
+	generator, _ := generator.New(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	passwords, errs := generator.Passwords(ctx)
+	for i := 0; i < 10; i++ {
+		assert.NotEmpty(t, <-passwords)
+	}
+	cancel()
+
+	// The channel may deliver a password that was already pending before it is closed
+	for range passwords {
+	}
+	assert.NoError(t, <-errs)
+}
+
+func TestGeneratePasswordsParallelShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generator, _ := generator.New(generatorConfig)
+	for _, workers := range []int{0, 1, 3, 64} {
+		passwords, err := generator.GeneratePasswordsParallel(50, workers)
+		assert.NoError(t, err)
+		assert.Len(t, passwords, 50)
+		for _, password := range passwords {
+			assert.Empty(t, generatorConfig.Check(password))
+		}
+	}
+	passwords, err := generator.GeneratePasswordsParallel(0, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, passwords)
+}
+
+func TestGeneratePasswordConcurrentlyShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generator, _ := generator.New(generatorConfig)
+
+	var wg sync.WaitGroup
+	passwords := make(chan string, 8*100)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				passwords <- generator.GeneratePassword()
+			}
+		}()
+	}
+	wg.Wait()
+	close(passwords)
+
+	for password := range passwords {
+		assert.Empty(t, generatorConfig.Check(password))
+	}
+}
+
+func BenchmarkGeneratePassword(b *testing.B) {
+	generator, _ := generator.New(nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generator.GeneratePassword()
+	}
+}
+
+func BenchmarkGeneratePasswordWithLength1000(b *testing.B) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.MinLength = 1000
+	generatorConfig.MaxLength = 1000
+	for i := 0; i < b.N; i++ {
+		generator, _ := generator.New(generatorConfig)
+		generator.GeneratePassword()
+	}
+}
+
+func BenchmarkNewWithLargeClassMinimums(b *testing.B) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.MinLength = 128
+	generatorConfig.MaxLength = 160
+	generatorConfig.MinLowerCaseLetters = 30
+	generatorConfig.MinUpperCaseLetters = 30
+	generatorConfig.MinDigits = 30
+	generatorConfig.MinSpecials = 30
+	for i := 0; i < b.N; i++ {
+		generator.New(generatorConfig)
+	}
+}
+
+func BenchmarkGeneratePasswordConcurrently(b *testing.B) {
+	generator, _ := generator.New(nil)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			generator.GeneratePassword()
+		}
+	})
+}
+
+func BenchmarkGeneratePasswordsParallel(b *testing.B) {
+	generator, _ := generator.New(nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		generator.GeneratePasswordsParallel(1000, 0)
+	}
+}
+
+func TestGeneratePasswordEShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generator, _ := generator.New(generatorConfig)
+	password, err := generator.GeneratePasswordE()
+	assert.NoError(t, err)
+	assert.Empty(t, generatorConfig.Check(password))
+}
+
+func TestGeneratePasswordWithSeedShouldMatchKnownAnswers(t *testing.T) {
+	testCases := []struct {
+		seed          string
+		pronounceable bool
+		expected      []string
+	}{
+		{
+			seed:     "strongpass known-answer seed 001",
+			expected: []string{"C{t#8H(6Fb/\\^OEc*0ueYUYRx.", "uh/qdcq<O*_2T=&{A[8M§)]¤{)", "3jbQwzvO=O9FbuVdaZ8Urs792n"},
+		},
+		{
+			seed:     "strongpass known-answer seed 002",
+			expected: []string{"AMVLKEjVX^!#qx^{+7}U2fhh#U", "?FM6ki+9ta£o.u1m3Mf;aB6lPr", "F-aL;Sz21gP,AI¤H9reA\\YZW_N"},
+		},
+		{
+			seed:          "strongpass known-answer seed 003",
+			pronounceable: true,
+			expected:      []string{"kamuhugujagolofuraviruvE@3", "savibosufuRivonofozogeho0-"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		source, err := rand.NewDRBG([]byte(testCase.seed))
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		generatorConfig := generator.DefaultConfig()
+		generatorConfig.Pronounceable = testCase.pronounceable
+		generatorConfig.Rand = source
+		generator, err := generator.New(generatorConfig)
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		passwords, err := generator.GeneratePasswords(len(testCase.expected))
+		assert.NoError(t, err)
+		assert.Equal(t, testCase.expected, passwords, testCase.seed)
+	}
+}
+
+func TestGeneratePasswordWithFailingRandShouldFail(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.Rand = bytes.NewReader(nil)
+	generator, _ := generator.New(generatorConfig)
+
+	_, err := generator.GeneratePasswordE()
+	assert.Error(t, err)
+	assert.Panics(t, func() {
+		generator.GeneratePassword()
+	})
+}
@@ -0,0 +1,186 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generator
+
+import (
+	"io"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+// MaxCustomCharSets is the number of custom character sets that a mask can refer to, as ?1 to ?4.
+const MaxCustomCharSets = 4
+
+// Mask is a positional password template in the style of hashcat, such as ?u?l?l?l-?d?d?d?d-?s.
+// Each position is either a literal character or a placeholder for one random character:
+//
+//	?l  lower-case letters
+//	?u  upper-case letters
+//	?d  digits
+//	?s  special symbols
+//	?a  all of the above
+//	?1  the first custom character set, up to ?4
+//	??  a literal question mark
+//
+// A Mask is safe for concurrent use by multiple goroutines, unless it has a Rand reader that is not.
+type Mask struct {
+	positions [][]rune
+	source    *rand.Source
+}
+
+// ParseMask parses a mask, where customCharSets are the custom character sets ?1 to ?4. A custom
+// character set is made up of literal characters and the built-in placeholders, such as ?l?d.
+func ParseMask(mask string, customCharSets ...string) (*Mask, error) {
+	if len(customCharSets) > MaxCustomCharSets {
+		return nil, errors.Errorf("A mask can have at most %d custom character sets", MaxCustomCharSets)
+	}
+
+	customRunes := make([][]rune, len(customCharSets))
+	for i, customCharSet := range customCharSets {
+		positions, err := parseMaskPositions(customCharSet, nil)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to parse custom character set %d", i+1)
+		}
+		for _, runes := range positions {
+			customRunes[i] = append(customRunes[i], runes...)
+		}
+		customRunes[i] = removeDuplicateRunes(customRunes[i])
+	}
+
+	positions, err := parseMaskPositions(mask, customRunes)
+	if err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 {
+		return nil, errors.New("The mask cannot be empty")
+	}
+	return &Mask{positions: positions, source: rand.Default()}, nil
+}
+
+// WithRand returns a copy of the mask that reads random bytes from the specified reader instead
+// of the cryptographically secure default, the same as the Rand field of Config. A deterministic
+// reader makes the generated passwords reproducible, which is only meant for tests.
+func (mask *Mask) WithRand(reader io.Reader) *Mask {
+	return &Mask{positions: mask.positions, source: rand.NewSource(reader)}
+}
+
+// Keyspace returns the number of distinct passwords that the mask can generate.
+func (mask *Mask) Keyspace() *big.Int {
+	keyspace := big.NewInt(1)
+	for _, runes := range mask.positions {
+		keyspace.Mul(keyspace, big.NewInt(int64(len(runes))))
+	}
+	return keyspace
+}
+
+// Entropy returns the entropy of the mask in bits, which is the base-2 logarithm of the keyspace.
+func (mask *Mask) Entropy() float64 {
+	return rand.Entropy(mask.Keyspace())
+}
+
+// Len returns the length of the passwords that the mask generates.
+func (mask *Mask) Len() int {
+	return len(mask.positions)
+}
+
+// GeneratePassword generates a password from the mask. It panics if the random source fails,
+// see GeneratePasswordE.
+func (mask *Mask) GeneratePassword() string {
+	password, err := mask.GeneratePasswordE()
+	if err != nil {
+		panic(err)
+	}
+	return password
+}
+
+// GeneratePasswordE generates a password from the mask, or returns an error if the random source fails.
+func (mask *Mask) GeneratePasswordE() (string, error) {
+	password := make([]rune, 0, len(mask.positions))
+	for _, runes := range mask.positions {
+		var err error
+		if password, err = appendRandomRunes(mask.source, password, runes, 1); err != nil {
+			return "", err
+		}
+	}
+	return string(password), nil
+}
+
+func parseMaskPositions(mask string, customRunes [][]rune) ([][]rune, error) {
+	runes := []rune(mask)
+	var positions [][]rune
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '?' {
+			positions = append(positions, []rune{runes[i]})
+			continue
+		}
+
+		i++
+		if i >= len(runes) {
+			return nil, errors.New("The mask ends with an incomplete placeholder, use ?? for a literal question mark")
+		}
+
+		placeholder := runes[i]
+		switch {
+		case placeholder == '?':
+			positions = append(positions, []rune{'?'})
+		case placeholder >= '1' && placeholder <= '0'+MaxCustomCharSets:
+			index := int(placeholder - '1')
+			if index >= len(customRunes) || len(customRunes[index]) == 0 {
+				return nil, errors.Errorf("The placeholder ?%c refers to a custom character set that is not defined", placeholder)
+			}
+			positions = append(positions, customRunes[index])
+		default:
+			builtIn := builtInMaskRunes(placeholder)
+			if builtIn == nil {
+				return nil, errors.Errorf("Unknown placeholder in mask: ?%c", placeholder)
+			}
+			positions = append(positions, builtIn)
+		}
+	}
+	return positions, nil
+}
+
+func builtInMaskRunes(placeholder rune) []rune {
+	switch placeholder {
+	case 'l':
+		return lowerCaseLetterRunes
+	case 'u':
+		return upperCaseLetterRunes
+	case 'd':
+		return digitRunes
+	case 's':
+		return specialRunes
+	case 'a':
+		var runes []rune
+		runes = append(runes, lowerCaseLetterRunes...)
+		runes = append(runes, upperCaseLetterRunes...)
+		runes = append(runes, digitRunes...)
+		return append(runes, specialRunes...)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,76 @@
+package generator_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/generator"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+func TestParseMaskShouldSucceed(t *testing.T) {
+	mask, err := generator.ParseMask("?u?l?l?l-?d?d?d?d-?s")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 11, mask.Len())
+
+	// The special symbols are the same pool as the generator uses
+	specialCount := int64(len(generator.Specials(0, 0).Runes))
+	assert.Equal(t, big.NewInt(26*26*26*26*10*10*10*10*specialCount), mask.Keyspace())
+
+	for i := 0; i < 50; i++ {
+		password := []rune(mask.GeneratePassword())
+		assert.Len(t, password, 11)
+		assert.True(t, password[0] >= 'A' && password[0] <= 'Z')
+		assert.Equal(t, '-', password[4])
+		assert.True(t, password[5] >= '0' && password[5] <= '9')
+		assert.Equal(t, '-', password[9])
+		assert.Contains(t, generator.Specials(0, 0).Runes, password[10])
+	}
+}
+
+func TestParseMaskWithCustomCharSetsShouldSucceed(t *testing.T) {
+	mask, err := generator.ParseMask("?1?2??x", "abc", "?d??")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, big.NewInt(3*11), mask.Keyspace())
+
+	for i := 0; i < 50; i++ {
+		password := mask.GeneratePassword()
+		assert.Contains(t, "abc", password[:1])
+		assert.Contains(t, "0123456789?", password[1:2])
+		assert.Equal(t, "?x", password[2:])
+	}
+}
+
+func TestParseMaskShouldFail(t *testing.T) {
+	invalidMasks := []string{"", "?", "abc?", "?x", "?1", "?5"}
+	for _, mask := range invalidMasks {
+		_, err := generator.ParseMask(mask)
+		assert.Error(t, err, mask)
+	}
+
+	_, err := generator.ParseMask("?1", "a", "b", "c", "d", "e")
+	assert.Error(t, err)
+}
+
+func TestMaskWithRandShouldMatchKnownAnswers(t *testing.T) {
+	mask, err := generator.ParseMask("?u?l?l?l-?d?d?d?d-?s")
+	if !assert.NoError(t, err) {
+		return
+	}
+	source, err := rand.NewDRBG([]byte("strongpass known-answer seed 001"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	seeded := mask.WithRand(source)
+	for _, expected := range []string{"Bdwi-0491-:", "Axap-0231->"} {
+		password, err := seeded.GeneratePasswordE()
+		assert.NoError(t, err)
+		assert.Equal(t, expected, password)
+	}
+}
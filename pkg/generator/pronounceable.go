@@ -0,0 +1,165 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generator
+
+import (
+	"math/big"
+	"unicode"
+
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+var (
+	// The letters of pronounceable passwords, without letters that are easily misheard, such as c and q
+	pronounceableConsonantRunes = []rune("bdfghjklmnprstvz")
+	pronounceableVowelRunes     = []rune("aeiou")
+	// The special symbols of pronounceable passwords, which all have well-known names
+	pronounceableSpecialRunes = []rune("!#%&*+-=?@")
+)
+
+// pronounceableSampler draws pronounceable passwords uniformly. A pronounceable password is
+// made up of alternating consonants and vowels, starting with a consonant, where exactly the
+// minimum number of upper-case letters are capitalized, followed by exactly the minimum number
+// of digits and special symbols in any order.
+type pronounceableSampler struct {
+	consonants    []rune
+	vowels        []rune
+	digits        []rune
+	specials      []rune
+	upperCount    int
+	maxLowerCount int
+	digitCount    int
+	specialCount  int
+	minLength     int
+
+	lengthWeights []*big.Int
+}
+
+func newPronounceableSampler(config *Config, minLength, maxLength int) *pronounceableSampler {
+	s := &pronounceableSampler{
+		consonants:    pronounceableConsonantRunes,
+		vowels:        pronounceableVowelRunes,
+		digits:        digitRunes,
+		specials:      pronounceableSpecialRunes,
+		upperCount:    config.MinUpperCaseLetters,
+		maxLowerCount: config.MaxLowerCaseLetters,
+		digitCount:    config.MinDigits,
+		specialCount:  config.MinSpecials,
+		minLength:     minLength,
+	}
+	if config.ExcludeAmbiguous {
+		// Letters are removed when either their lower-case or upper-case form is ambiguous
+		ambiguous := append([]rune("i"), ambiguousRunes...)
+		s.consonants = removeRunes(s.consonants, ambiguous)
+		s.vowels = removeRunes(s.vowels, ambiguous)
+		s.digits = removeRunes(s.digits, ambiguous)
+	}
+
+	for length := minLength; length <= maxLength; length++ {
+		s.lengthWeights = append(s.lengthWeights, s.countOfLength(length))
+	}
+	return s
+}
+
+func (s *pronounceableSampler) count() *big.Int {
+	return sum(s.lengthWeights)
+}
+
+func (s *pronounceableSampler) countOfLength(length int) *big.Int {
+	letterCount := length - s.digitCount - s.specialCount
+	if letterCount < s.upperCount || (s.maxLowerCount > 0 && letterCount-s.upperCount > s.maxLowerCount) {
+		return new(big.Int)
+	}
+
+	consonantCount := (letterCount + 1) / 2
+	count := new(big.Int).Exp(big.NewInt(int64(len(s.consonants))), big.NewInt(int64(consonantCount)), nil)
+	count.Mul(count, new(big.Int).Exp(big.NewInt(int64(len(s.vowels))), big.NewInt(int64(letterCount-consonantCount)), nil))
+	count.Mul(count, new(big.Int).Binomial(int64(letterCount), int64(s.upperCount)))
+	count.Mul(count, new(big.Int).Binomial(int64(s.digitCount+s.specialCount), int64(s.digitCount)))
+	count.Mul(count, new(big.Int).Exp(big.NewInt(int64(len(s.digits))), big.NewInt(int64(s.digitCount)), nil))
+	count.Mul(count, new(big.Int).Exp(big.NewInt(int64(len(s.specials))), big.NewInt(int64(s.specialCount)), nil))
+	return count
+}
+
+func (s *pronounceableSampler) sample(source *rand.Source) ([]rune, error) {
+	lengthIndex, err := source.WeightedIntnE(s.lengthWeights)
+	if err != nil {
+		return nil, err
+	}
+	length := s.minLength + lengthIndex
+	letterCount := length - s.digitCount - s.specialCount
+
+	password := make([]rune, 0, length)
+	for i := 0; i < letterCount; i++ {
+		letters := s.consonants
+		if i%2 == 1 {
+			letters = s.vowels
+		}
+		if password, err = appendRandomRunes(source, password, letters, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	positions := make([]int, letterCount)
+	for i := range positions {
+		positions[i] = i
+	}
+	err = source.ShuffleE(len(positions), func(i, j int) {
+		positions[i], positions[j] = positions[j], positions[i]
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, position := range positions[:s.upperCount] {
+		password[position] = unicode.ToUpper(password[position])
+	}
+
+	tail, err := appendRandomRunes(source, make([]rune, 0, s.digitCount+s.specialCount), s.digits, s.digitCount)
+	if err != nil {
+		return nil, err
+	}
+	if tail, err = appendRandomRunes(source, tail, s.specials, s.specialCount); err != nil {
+		return nil, err
+	}
+	err = source.ShuffleE(len(tail), func(i, j int) {
+		tail[i], tail[j] = tail[j], tail[i]
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(password, tail...), nil
+}
+
+// appendRandomRunes appends count random runes to the buffer.
+func appendRandomRunes(source *rand.Source, buffer []rune, runes []rune, count int) ([]rune, error) {
+	for i := 0; i < count; i++ {
+		index, err := source.IntnE(len(runes))
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, runes[index])
+	}
+	return buffer, nil
+}
@@ -0,0 +1,82 @@
+package generator_test
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/generator"
+)
+
+func TestGeneratePronounceablePasswordShouldSucceed(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.Pronounceable = true
+	generator, err := generator.New(generatorConfig)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i := 0; i < 50; i++ {
+		password := generator.GeneratePassword()
+		assert.True(t, len(password) >= generatorConfig.MinLength && len(password) <= generatorConfig.MaxLength, password)
+		assert.Empty(t, generatorConfig.Check(password), password)
+
+		letters := []rune(strings.ToLower(password[:len(password)-2]))
+		for j, r := range letters {
+			if j%2 == 0 {
+				assert.Contains(t, "bdfghjklmnprstvz", string(r), password)
+			} else {
+				assert.Contains(t, "aeiou", string(r), password)
+			}
+		}
+		assert.Equal(t, 1, countRunes(password, unicode.IsUpper), password)
+	}
+}
+
+func TestGeneratorKeyspacePronounceableShouldSucceed(t *testing.T) {
+	generatorConfig := generator.Config{
+		Pronounceable:         true,
+		AllowLowerCaseLetters: true,
+		AllowUpperCaseLetters: true,
+		AllowDigits:           true,
+		MinLength:             3,
+		MaxLength:             3,
+		MinUpperCaseLetters:   1,
+		MinDigits:             1,
+	}
+	generator, err := generator.New(&generatorConfig)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// A consonant and a vowel, one of which is upper-case, followed by a digit
+	assert.Equal(t, big.NewInt(16*5*2*10), generator.Keyspace())
+}
+
+func TestGeneratorEntropyPronounceableShouldBeLower(t *testing.T) {
+	randomGenerator, _ := generator.New(nil)
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.Pronounceable = true
+	pronounceableGenerator, _ := generator.New(generatorConfig)
+	assert.Less(t, pronounceableGenerator.Entropy(), randomGenerator.Entropy())
+}
+
+func TestNewPronounceableWithCharSetShouldFail(t *testing.T) {
+	generatorConfig := generator.DefaultConfig()
+	generatorConfig.Pronounceable = true
+	generatorConfig.CharSet = []rune("abc")
+	_, err := generator.New(generatorConfig)
+	assert.Error(t, err)
+}
+
+func countRunes(s string, predicate func(rune) bool) int {
+	count := 0
+	for _, r := range s {
+		if predicate(r) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,355 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package generator
+
+import (
+	"math/big"
+
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+// passwordSampler draws passwords uniformly from a set of passwords.
+type passwordSampler interface {
+	sample(source *rand.Source) ([]rune, error)
+	count() *big.Int
+}
+
+// maxRejectionCount is the highest expected number of tries for which passwords of a length are
+// drawn by rejection sampling. Lengths with fewer valid passwords are drawn by exact counting.
+const maxRejectionCount = 16
+
+// sampler draws passwords uniformly from all the passwords that satisfy a policy. The length is
+// drawn first, weighted by the exact number of valid passwords of each length. Then, when at least
+// one in maxRejectionCount of all strings of that length is valid, strings are drawn uniformly
+// from the whole character set until one satisfies the class bounds. Otherwise the number of
+// characters of each class, the positions of the classes and finally the characters themselves
+// are drawn in turn, each weighted by the exact number of passwords that it leads to. Either way
+// every valid password is equally likely.
+type sampler struct {
+	classes      [][]rune
+	mins         []int
+	maxs         []int
+	rest         []rune
+	alphabet     []rune
+	classIndexes map[rune]int
+	minLength    int
+
+	lengthWeights []*big.Int
+	// exact[i] is whether passwords with the ith length are drawn by exact counting
+	exact []bool
+
+	// ways[i][n] is the number of ways to fill n positions with the classes from i onwards
+	// and the characters without a class, with every class within its bounds. The tables are
+	// only built up to the longest length that is drawn by exact counting.
+	ways [][]*big.Int
+	// classCountWeights[i][n] is the number of ways to fill n positions with the classes from i
+	// onwards, for each number of characters of class i starting at its minimum
+	classCountWeights [][][]*big.Int
+}
+
+func newSampler(charSet []rune, classes []CharClass, minLength, maxLength int) *sampler {
+	s := &sampler{minLength: minLength}
+	for _, class := range classes {
+		runes := removeDuplicateRunes(class.Runes)
+		if len(runes) == 0 {
+			// Classes without characters cannot have a minimum, so they never take part
+			continue
+		}
+		s.classes = append(s.classes, runes)
+		s.mins = append(s.mins, class.Min)
+		s.maxs = append(s.maxs, class.Max)
+	}
+	s.rest = removeRunes(removeDuplicateRunes(charSet), classRunes(s.classes))
+	s.alphabet = append(classRunes(s.classes), s.rest...)
+	s.classIndexes = make(map[rune]int)
+	for i, class := range s.classes {
+		for _, r := range class {
+			s.classIndexes[r] = i
+		}
+	}
+
+	bounds := make([]classBounds, len(s.classes))
+	for i, class := range s.classes {
+		bounds[i] = classBounds{size: int64(len(class)), min: s.mins[i], max: s.maxs[i]}
+	}
+	alphabetSize := big.NewInt(int64(len(s.alphabet)))
+	counts := countWithinBounds(int64(len(s.alphabet)), bounds, minLength, maxLength)
+	exactLength := -1
+	for length := minLength; length <= maxLength; length++ {
+		count := counts[length]
+		total := new(big.Int).Exp(alphabetSize, big.NewInt(int64(length)), nil)
+		exact := count.Sign() > 0 && new(big.Int).Mul(count, big.NewInt(maxRejectionCount)).Cmp(total) < 0
+		if exact {
+			exactLength = length
+		}
+		s.lengthWeights = append(s.lengthWeights, count)
+		s.exact = append(s.exact, exact)
+	}
+	if exactLength >= 0 {
+		s.buildTables(exactLength)
+	}
+	return s
+}
+
+// count returns the number of passwords that the sampler draws from.
+func (s *sampler) count() *big.Int {
+	return sum(s.lengthWeights)
+}
+
+func (s *sampler) sample(source *rand.Source) ([]rune, error) {
+	lengthIndex, err := source.WeightedIntnE(s.lengthWeights)
+	if err != nil {
+		return nil, err
+	}
+	length := s.minLength + lengthIndex
+	if s.exact[lengthIndex] {
+		return s.sampleExact(source, length)
+	}
+
+	// Every string of the length is equally likely, so the first valid one is a uniform draw
+	password := make([]rune, 0, length)
+	for {
+		if password, err = appendRandomRunes(source, password[:0], s.alphabet, length); err != nil {
+			return nil, err
+		}
+		if s.isWithinBounds(password) {
+			return password, nil
+		}
+	}
+}
+
+func (s *sampler) isWithinBounds(password []rune) bool {
+	classCounts := make([]int, len(s.classes))
+	for _, r := range password {
+		if classIndex, ok := s.classIndexes[r]; ok {
+			classCounts[classIndex]++
+		}
+	}
+	for i, classCount := range classCounts {
+		if classCount < s.mins[i] || (s.maxs[i] > 0 && classCount > s.maxs[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *sampler) sampleExact(source *rand.Source, length int) ([]rune, error) {
+	// Every arrangement of the classes is equally likely for the same number of each class
+	labels := make([]int, 0, length)
+	remaining := length
+	for i := range s.classes {
+		countIndex, err := source.WeightedIntnE(s.classCountWeights[i][remaining])
+		if err != nil {
+			return nil, err
+		}
+		classCount := s.mins[i] + countIndex
+		for j := 0; j < classCount; j++ {
+			labels = append(labels, i)
+		}
+		remaining -= classCount
+	}
+	for j := 0; j < remaining; j++ {
+		labels = append(labels, -1)
+	}
+	err := source.ShuffleE(len(labels), func(i, j int) {
+		labels[i], labels[j] = labels[j], labels[i]
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	password := make([]rune, 0, length)
+	for _, label := range labels {
+		runes := s.rest
+		if label >= 0 {
+			runes = s.classes[label]
+		}
+		if password, err = appendRandomRunes(source, password, runes, 1); err != nil {
+			return nil, err
+		}
+	}
+	return password, nil
+}
+
+func (s *sampler) buildTables(maxLength int) {
+	s.ways = make([][]*big.Int, len(s.classes)+1)
+	restWays := make([]*big.Int, maxLength+1)
+	for n := range restWays {
+		restWays[n] = new(big.Int).Exp(big.NewInt(int64(len(s.rest))), big.NewInt(int64(n)), nil)
+	}
+	s.ways[len(s.classes)] = restWays
+	s.classCountWeights = make([][][]*big.Int, len(s.classes))
+	for i := len(s.classes) - 1; i >= 0; i-- {
+		s.ways[i] = make([]*big.Int, maxLength+1)
+		s.classCountWeights[i] = make([][]*big.Int, maxLength+1)
+		for n := range s.ways[i] {
+			s.classCountWeights[i][n] = s.countClassCountWeights(i, n)
+			s.ways[i][n] = sum(s.classCountWeights[i][n])
+		}
+	}
+}
+
+func (s *sampler) countClassCountWeights(i, n int) []*big.Int {
+	size := big.NewInt(int64(len(s.classes[i])))
+	var weights []*big.Int
+	classCount := s.mins[i]
+	if classCount > n {
+		return weights
+	}
+	// The binomial coefficient and the power are updated from one class count to the next
+	binomial := new(big.Int).Binomial(int64(n), int64(classCount))
+	power := new(big.Int).Exp(size, big.NewInt(int64(classCount)), nil)
+	for ; classCount <= n && (s.maxs[i] == 0 || classCount <= s.maxs[i]); classCount++ {
+		weight := new(big.Int).Mul(binomial, power)
+		weight.Mul(weight, s.ways[i+1][n-classCount])
+		weights = append(weights, weight)
+		binomial.Mul(binomial, big.NewInt(int64(n-classCount)))
+		binomial.Quo(binomial, big.NewInt(int64(classCount+1)))
+		power.Mul(power, size)
+	}
+	return weights
+}
+
+// classBounds is the number of distinct runes of a character class, and the minimum and maximum
+// number of them in a password.
+type classBounds struct {
+	size int64
+	min  int
+	max  int
+}
+
+// countWithinBounds counts the strings of every length from minLength to maxLength, indexed by
+// their length, over an alphabet of the specified size that contain at least the minimum and at most the maximum number of runes from
+// each class. A string is excluded when any class is below its minimum, so by inclusion-exclusion
+// the count is the alternating sum, over every subset of the classes with minimums, of the number
+// of strings in which all classes of the subset are below their minimums and all other classes are
+// within their maximums. Only the classes with minimums make up the subsets, and each term is
+// counted for all lengths at once.
+func countWithinBounds(alphabetSize int64, classes []classBounds, minLength, maxLength int) []*big.Int {
+	var constrained []int
+	for i, class := range classes {
+		if class.min > 0 {
+			constrained = append(constrained, i)
+		}
+	}
+
+	counts := make([]*big.Int, maxLength+1)
+	for n := minLength; n <= maxLength; n++ {
+		counts[n] = new(big.Int)
+	}
+	subsetCount := 1 << uint(len(constrained))
+	for subset := 0; subset < subsetCount; subset++ {
+		below := make(map[int]bool)
+		for bit, classIndex := range constrained {
+			if subset&(1<<uint(bit)) != 0 {
+				below[classIndex] = true
+			}
+		}
+
+		var bounded []classBounds
+		restSize := alphabetSize
+		for i, class := range classes {
+			if below[i] {
+				bounded = append(bounded, classBounds{size: class.size, max: class.min - 1})
+				restSize -= class.size
+			} else if class.max > 0 && class.max < maxLength {
+				// A maximum of at least the length never excludes anything
+				bounded = append(bounded, class)
+				restSize -= class.size
+			}
+		}
+
+		terms := countWithMaximums(bounded, restSize, minLength, maxLength)
+		for n := minLength; n <= maxLength; n++ {
+			if len(below)%2 == 0 {
+				counts[n].Add(counts[n], terms[n])
+			} else {
+				counts[n].Sub(counts[n], terms[n])
+			}
+		}
+	}
+	return counts
+}
+
+// countWithMaximums counts the strings of every length from minLength to maxLength, indexed by
+// their length, in which every specified class appears at most its maximum number of times, while
+// the remaining positions are taken from the rest of the alphabet. The table of counts is built
+// from the last class to the first, where the counts for class i and n positions are summed from
+// the counts for class i+1 and n down to n minus the maximum of class i. Only the lengths that the
+// shortest count depends on are counted.
+func countWithMaximums(classes []classBounds, restSize int64, minLength, maxLength int) []*big.Int {
+	lowest := make([]int, len(classes)+1)
+	lowest[0] = minLength
+	for i, class := range classes {
+		lowest[i+1] = lowest[i] - class.max
+		if lowest[i+1] < 0 {
+			lowest[i+1] = 0
+		}
+	}
+
+	counts := make([]*big.Int, maxLength+1)
+	power := new(big.Int).Exp(big.NewInt(restSize), big.NewInt(int64(lowest[len(classes)])), nil)
+	for n := lowest[len(classes)]; n <= maxLength; n++ {
+		counts[n] = new(big.Int).Set(power)
+		power.Mul(power, big.NewInt(restSize))
+	}
+
+	for i := len(classes) - 1; i >= 0; i-- {
+		class := classes[i]
+		size := big.NewInt(class.size)
+		next := make([]*big.Int, maxLength+1)
+		for n := lowest[i]; n <= maxLength; n++ {
+			next[n] = new(big.Int)
+			binomial := big.NewInt(1)
+			power := big.NewInt(1)
+			for classCount := 0; classCount <= class.max && classCount <= n; classCount++ {
+				term := new(big.Int).Mul(binomial, power)
+				term.Mul(term, counts[n-classCount])
+				next[n].Add(next[n], term)
+				binomial.Mul(binomial, big.NewInt(int64(n-classCount)))
+				binomial.Quo(binomial, big.NewInt(int64(classCount+1)))
+				power.Mul(power, size)
+			}
+		}
+		counts = next
+	}
+	return counts
+}
+
+func sum(values []*big.Int) *big.Int {
+	total := new(big.Int)
+	for _, value := range values {
+		total.Add(total, value)
+	}
+	return total
+}
+
+func classRunes(classes [][]rune) []rune {
+	var runes []rune
+	for _, class := range classes {
+		runes = append(runes, class...)
+	}
+	return runes
+}
@@ -0,0 +1,311 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package otp generates one-time password secrets and implements HOTP (RFC 4226) and TOTP (RFC 6238).
+package otp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+// Algorithm is the HMAC hash algorithm of one-time passwords.
+type Algorithm string
+
+const (
+	// SHA1 is the default algorithm, and the only one that all authenticator apps support.
+	SHA1 Algorithm = "SHA1"
+	// SHA256 is HMAC-SHA-256.
+	SHA256 Algorithm = "SHA256"
+	// SHA512 is HMAC-SHA-512.
+	SHA512 Algorithm = "SHA512"
+)
+
+// DefaultSecretSize is the default size of secrets in bytes, which is the output size of SHA-1
+// as RFC 4226 recommends.
+const DefaultSecretSize = 20
+
+// MinSecretSize is the minimum size of new secrets in bytes, which is the 128 bits that RFC 4226 requires.
+const MinSecretSize = 16
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Key is a TOTP key with the parameters that authenticator apps need.
+type Key struct {
+	Secret    []byte
+	Issuer    string
+	Account   string
+	Digits    int
+	Period    int
+	Algorithm Algorithm
+}
+
+// ParseAlgorithm returns the algorithm with the specified name, such as SHA1 or sha-256.
+func ParseAlgorithm(name string) (Algorithm, error) {
+	switch strings.Replace(strings.ToUpper(name), "-", "", -1) {
+	case "SHA1":
+		return SHA1, nil
+	case "SHA256":
+		return SHA256, nil
+	case "SHA512":
+		return SHA512, nil
+	default:
+		return "", errors.Errorf("Unknown algorithm: %s", name)
+	}
+}
+
+// NewKey returns a new key with a random secret of the specified size in bytes, and the
+// default parameters of 6 digits, a period of 30 seconds and SHA1.
+func NewKey(issuer, account string, secretSize int) (*Key, error) {
+	if secretSize < MinSecretSize {
+		return nil, errors.Errorf("The secret size cannot be lower than %d bytes", MinSecretSize)
+	}
+
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.Wrap(err, "Failed to generate a secret")
+	}
+	return &Key{
+		Secret:    secret,
+		Issuer:    issuer,
+		Account:   account,
+		Digits:    6,
+		Period:    30,
+		Algorithm: SHA1,
+	}, nil
+}
+
+// ParseURI parses an otpauth://totp/ URI, where missing parameters have their default values.
+func ParseURI(uri string) (*Key, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the URI")
+	}
+	if parsed.Scheme != "otpauth" || parsed.Host != "totp" {
+		return nil, errors.New("The URI is not an otpauth://totp/ URI")
+	}
+
+	query := parsed.Query()
+	secret, err := DecodeSecret(query.Get("secret"))
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		Secret:    secret,
+		Issuer:    query.Get("issuer"),
+		Digits:    6,
+		Period:    30,
+		Algorithm: SHA1,
+	}
+	// The escaped path is split, so that an escaped colon in the issuer or account is not taken as the separator
+	label := strings.TrimPrefix(parsed.EscapedPath(), "/")
+	if i := strings.Index(label, ":"); i >= 0 {
+		issuer, err := url.PathUnescape(label[:i])
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to parse the issuer of the URI")
+		}
+		if key.Issuer == "" {
+			key.Issuer = issuer
+		}
+		label = strings.TrimLeft(label[i+1:], " ")
+	}
+	if key.Account, err = url.PathUnescape(label); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the account of the URI")
+	}
+
+	if digits := query.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return nil, errors.Errorf("The digits of the URI are not a number: %s", digits)
+		}
+	}
+	if period := query.Get("period"); period != "" {
+		if key.Period, err = strconv.Atoi(period); err != nil {
+			return nil, errors.Errorf("The period of the URI is not a number: %s", period)
+		}
+	}
+	if algorithm := query.Get("algorithm"); algorithm != "" {
+		if key.Algorithm, err = ParseAlgorithm(algorithm); err != nil {
+			return nil, err
+		}
+	}
+	return key, key.validate()
+}
+
+// DecodeSecret decodes a base32 secret, ignoring case, spaces and padding.
+func DecodeSecret(encoded string) ([]byte, error) {
+	encoded = strings.ToUpper(strings.Replace(encoded, " ", "", -1))
+	secret, err := secretEncoding.DecodeString(strings.TrimRight(encoded, "="))
+	if err != nil {
+		return nil, errors.Wrap(err, "The secret is not valid base32")
+	}
+	if len(secret) == 0 {
+		return nil, errors.New("The secret cannot be empty")
+	}
+	return secret, nil
+}
+
+// EncodedSecret returns the secret in base32 without padding, as authenticator apps expect it.
+func (key *Key) EncodedSecret() string {
+	return secretEncoding.EncodeToString(key.Secret)
+}
+
+// URI returns the otpauth://totp/ URI of the key, which authenticator apps read from QR codes.
+func (key *Key) URI() string {
+	label := escapeLabel(key.Account)
+	if key.Issuer != "" {
+		label = escapeLabel(key.Issuer) + ":" + label
+	}
+
+	query := url.Values{}
+	query.Set("secret", key.EncodedSecret())
+	if key.Issuer != "" {
+		query.Set("issuer", key.Issuer)
+	}
+	query.Set("algorithm", string(key.Algorithm))
+	query.Set("digits", strconv.Itoa(key.Digits))
+	query.Set("period", strconv.Itoa(key.Period))
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Code returns the TOTP code of the key at the specified time.
+func (key *Key) Code(t time.Time) (string, error) {
+	if err := key.validate(); err != nil {
+		return "", err
+	}
+	counter, err := key.counter(t)
+	if err != nil {
+		return "", err
+	}
+	return HOTP(key.Secret, counter, key.Digits, key.Algorithm)
+}
+
+// Verify returns whether the code is the TOTP code of the key at the specified time, or of up to
+// skew periods before or after it, which allows for clock drift.
+func (key *Key) Verify(code string, t time.Time, skew int) (bool, error) {
+	if err := key.validate(); err != nil {
+		return false, err
+	}
+
+	counter, err := key.counter(t)
+	if err != nil {
+		return false, err
+	}
+	valid := false
+	for offset := -skew; offset <= skew; offset++ {
+		if offset < 0 && uint64(-offset) > counter {
+			continue
+		}
+		expected, err := HOTP(key.Secret, counter+uint64(offset), key.Digits, key.Algorithm)
+		if err != nil {
+			return false, err
+		}
+		// Every code is compared, so the time taken does not reveal which one matched
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			valid = true
+		}
+	}
+	return valid, nil
+}
+
+// HOTP returns the HOTP code of the secret and counter, as specified by RFC 4226.
+func HOTP(secret []byte, counter uint64, digits int, algorithm Algorithm) (string, error) {
+	if digits < 6 || digits > 10 {
+		return "", errors.New("The number of digits must be between 6 and 10")
+	}
+	newHash, err := algorithm.hash()
+	if err != nil {
+		return "", err
+	}
+
+	var message [8]byte
+	binary.BigEndian.PutUint64(message[:], counter)
+	mac := hmac.New(newHash, secret)
+	mac.Write(message[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation takes 31 bits at the offset of the last 4 bits of the HMAC
+	offset := sum[len(sum)-1] & 0x0f
+	value := uint64(binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff)
+	modulus := uint64(1)
+	for i := 0; i < digits; i++ {
+		modulus *= 10
+	}
+	return fmt.Sprintf("%0*d", digits, value%modulus), nil
+}
+
+func (key *Key) counter(t time.Time) (uint64, error) {
+	seconds := t.Unix()
+	if seconds < 0 {
+		return 0, errors.New("The time cannot be before 1970")
+	}
+	return uint64(seconds) / uint64(key.Period), nil
+}
+
+// escapeLabel escapes an issuer or account name for the label of a URI, including the colon that separates them.
+func escapeLabel(name string) string {
+	return strings.Replace(url.PathEscape(name), ":", "%3A", -1)
+}
+
+func (key *Key) validate() error {
+	if len(key.Secret) == 0 {
+		return errors.New("The secret cannot be empty")
+	}
+	if key.Period <= 0 {
+		return errors.New("The period must be over zero")
+	}
+	if key.Digits < 6 || key.Digits > 10 {
+		return errors.New("The number of digits must be between 6 and 10")
+	}
+	_, err := key.Algorithm.hash()
+	return err
+}
+
+func (algorithm Algorithm) hash() (func() hash.Hash, error) {
+	switch algorithm {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	default:
+		return nil, errors.Errorf("Unknown algorithm: %s", algorithm)
+	}
+}
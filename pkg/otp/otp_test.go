@@ -0,0 +1,132 @@
+package otp_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/otp"
+)
+
+func TestHOTPShouldMatchRFC4226(t *testing.T) {
+	// RFC 4226, appendix D
+	secret := []byte("12345678901234567890")
+	expected := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range expected {
+		actual, err := otp.HOTP(secret, uint64(counter), 6, otp.SHA1)
+		assert.NoError(t, err)
+		assert.Equal(t, code, actual, "counter %d", counter)
+	}
+}
+
+func TestTOTPShouldMatchRFC6238(t *testing.T) {
+	// RFC 6238, appendix B
+	secrets := map[otp.Algorithm][]byte{
+		otp.SHA1:   []byte("12345678901234567890"),
+		otp.SHA256: []byte("12345678901234567890123456789012"),
+		otp.SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+	testCases := []struct {
+		time  int64
+		codes map[otp.Algorithm]string
+	}{
+		{59, map[otp.Algorithm]string{otp.SHA1: "94287082", otp.SHA256: "46119246", otp.SHA512: "90693936"}},
+		{1111111109, map[otp.Algorithm]string{otp.SHA1: "07081804", otp.SHA256: "68084774", otp.SHA512: "25091201"}},
+		{1111111111, map[otp.Algorithm]string{otp.SHA1: "14050471", otp.SHA256: "67062674", otp.SHA512: "99943326"}},
+		{1234567890, map[otp.Algorithm]string{otp.SHA1: "89005924", otp.SHA256: "91819424", otp.SHA512: "93441116"}},
+		{2000000000, map[otp.Algorithm]string{otp.SHA1: "69279037", otp.SHA256: "90698825", otp.SHA512: "38618901"}},
+		{20000000000, map[otp.Algorithm]string{otp.SHA1: "65353130", otp.SHA256: "77737706", otp.SHA512: "47863826"}},
+	}
+
+	for _, testCase := range testCases {
+		for algorithm, code := range testCase.codes {
+			key := &otp.Key{Secret: secrets[algorithm], Digits: 8, Period: 30, Algorithm: algorithm}
+			actual, err := key.Code(time.Unix(testCase.time, 0))
+			assert.NoError(t, err)
+			assert.Equal(t, code, actual, "%s at %d", algorithm, testCase.time)
+		}
+	}
+}
+
+func TestVerifyShouldSucceed(t *testing.T) {
+	key := &otp.Key{Secret: []byte("12345678901234567890"), Digits: 8, Period: 30, Algorithm: otp.SHA1}
+	now := time.Unix(59, 0)
+
+	valid, err := key.Verify("94287082", now, 0)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+
+	// The code of the previous period is only valid with a skew
+	previous := now.Add(30 * time.Second)
+	valid, _ = key.Verify("94287082", previous, 0)
+	assert.False(t, valid)
+	valid, _ = key.Verify("94287082", previous, 1)
+	assert.True(t, valid)
+
+	valid, _ = key.Verify("00000000", now, 1)
+	assert.False(t, valid)
+}
+
+func TestNewKeyURIShouldRoundTrip(t *testing.T) {
+	key, err := otp.NewKey("Example Corp", "svc-deploy@example.com", otp.DefaultSecretSize)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, key.Secret, otp.DefaultSecretSize)
+	assert.Len(t, key.EncodedSecret(), 32)
+
+	uri := key.URI()
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Example%20Corp:svc-deploy@example.com?"), uri)
+	assert.Contains(t, uri, "secret="+key.EncodedSecret())
+	assert.Contains(t, uri, "issuer=Example+Corp")
+	assert.Contains(t, uri, "algorithm=SHA1")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+
+	parsed, err := otp.ParseURI(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, key, parsed)
+}
+
+func TestURIWithSeparatorsInLabelShouldRoundTrip(t *testing.T) {
+	key := &otp.Key{
+		Secret:    []byte("1234567890123456"),
+		Issuer:    "Example: Corp/EU",
+		Account:   "ops:deploy/ci",
+		Digits:    6,
+		Period:    30,
+		Algorithm: otp.SHA1,
+	}
+
+	uri := key.URI()
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Example%3A%20Corp%2FEU:ops%3Adeploy%2Fci?"), uri)
+
+	parsed, err := otp.ParseURI(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, key, parsed)
+}
+
+func TestDecodeSecretShouldSucceed(t *testing.T) {
+	secret, err := otp.DecodeSecret("gezd gnbv gy3t qojq gezd gnbv gy3t qojq")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("12345678901234567890"), secret)
+}
+
+func TestOTPShouldFail(t *testing.T) {
+	_, err := otp.NewKey("", "account", 10)
+	assert.Error(t, err)
+	key := &otp.Key{Secret: []byte("12345678901234567890"), Digits: 6, Period: 30, Algorithm: otp.SHA1}
+	_, err = key.Code(time.Unix(-1, 0))
+	assert.Error(t, err)
+	_, err = key.Verify("000000", time.Unix(-1, 0), 1)
+	assert.Error(t, err)
+	_, err = otp.DecodeSecret("not base32!")
+	assert.Error(t, err)
+	_, err = otp.ParseURI("otpauth://hotp/account?secret=GEZDGNBV")
+	assert.Error(t, err)
+	_, err = otp.ParseAlgorithm("md5")
+	assert.Error(t, err)
+	_, err = otp.HOTP([]byte("secret"), 0, 5, otp.SHA1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,266 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package passphrase
+
+import (
+	"math/big"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/internal/wordlist"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+// Wordlist is one of the embedded wordlists.
+type Wordlist int
+
+const (
+	// EFFLarge is the EFF large wordlist with 7772 words, without its 4 hyphenated words.
+	EFFLarge Wordlist = iota
+	// EFFShort is the EFF short wordlist with 1295 words, without its hyphenated word.
+	EFFShort
+)
+
+var (
+	digitRunes   = []rune("0123456789")
+	specialRunes = []rune("!#%&()*+-./:;=?@[]_{}~")
+
+	// The hyphenated words, such as t-shirt, are left out, since a passphrase with them would
+	// have more words than it has when it is split by the default separator
+	effLargeWords = withoutHyphenatedWords(wordlist.EFFLarge)
+	effShortWords = withoutHyphenatedWords(wordlist.EFFShort)
+)
+
+// Generator is a passphrase generator. A Generator is safe for concurrent use by multiple goroutines.
+type Generator struct {
+	words         []string
+	wordCount     int
+	separator     string
+	capitalize    bool
+	insertDigit   bool
+	insertSpecial bool
+	digitRunes    []rune
+	specialRunes  []rune
+}
+
+// Config is the passphrase generator configuration.
+type Config struct {
+	Wordlist  Wordlist
+	WordCount int
+	// Separator is the separator between words, which cannot be empty.
+	Separator string
+
+	// Capitalize makes each word start with an upper-case letter with a 50% chance.
+	Capitalize bool
+	// InsertDigit inserts a random digit at a random position within a random word.
+	InsertDigit bool
+	// InsertSpecial inserts a random special symbol at a random position within a random word.
+	// The inserted digits and special symbols are never characters of the separator.
+	InsertSpecial bool
+}
+
+// New returns a new passphrase generator. If config is nil, the default configuration is used.
+func New(config *Config) (*Generator, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	return &Generator{
+		words:         config.Wordlist.words(),
+		wordCount:     config.WordCount,
+		separator:     config.Separator,
+		capitalize:    config.Capitalize,
+		insertDigit:   config.InsertDigit,
+		insertSpecial: config.InsertSpecial,
+		digitRunes:    removeRunesIn(digitRunes, config.Separator),
+		specialRunes:  removeRunesIn(specialRunes, config.Separator),
+	}, nil
+}
+
+// DefaultConfig returns the default configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		Wordlist:  EFFLarge,
+		WordCount: 6,
+		Separator: "-",
+	}
+}
+
+// ParseWordlist returns the wordlist with the specified name, either "large" or "short".
+func ParseWordlist(name string) (Wordlist, error) {
+	switch strings.ToLower(name) {
+	case "large", "eff-large":
+		return EFFLarge, nil
+	case "short", "eff-short":
+		return EFFShort, nil
+	default:
+		return 0, errors.Errorf("Unknown wordlist: %s", name)
+	}
+}
+
+// Keyspace returns the number of distinct passphrases of the words and their capitalization. The inserted digit and
+// special symbol are not counted, so it is a lower bound of the number of distinct passphrases.
+func (gen *Generator) Keyspace() *big.Int {
+	choices := int64(len(gen.words))
+	if gen.capitalize {
+		choices *= 2
+	}
+	return new(big.Int).Exp(big.NewInt(choices), big.NewInt(int64(gen.wordCount)), nil)
+}
+
+// Entropy returns the entropy of a passphrase in bits, which is the logarithm of Keyspace and also a lower bound.
+func (gen *Generator) Entropy() float64 {
+	return rand.Entropy(gen.Keyspace())
+}
+
+// GeneratePassphrase generates a passphrase. It panics if the random source fails, see GeneratePassphraseE.
+func (gen *Generator) GeneratePassphrase() string {
+	phrase, err := gen.GeneratePassphraseE()
+	if err != nil {
+		panic(err)
+	}
+	return phrase
+}
+
+// GeneratePassphraseE generates a passphrase, or returns an error if the random source fails.
+func (gen *Generator) GeneratePassphraseE() (string, error) {
+	words := make([]string, gen.wordCount)
+	for i := range words {
+		index, err := rand.IntnE(len(gen.words))
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to generate a passphrase")
+		}
+		word := gen.words[index]
+		if gen.capitalize {
+			coin, err := rand.IntnE(2)
+			if err != nil {
+				return "", errors.Wrap(err, "Failed to generate a passphrase")
+			}
+			if coin == 1 {
+				word = capitalizeWord(word)
+			}
+		}
+		words[i] = word
+	}
+
+	if gen.insertDigit {
+		if err := insertRandomRune(words, gen.digitRunes); err != nil {
+			return "", errors.Wrap(err, "Failed to generate a passphrase")
+		}
+	}
+	if gen.insertSpecial {
+		if err := insertRandomRune(words, gen.specialRunes); err != nil {
+			return "", errors.Wrap(err, "Failed to generate a passphrase")
+		}
+	}
+
+	return strings.Join(words, gen.separator), nil
+}
+
+func (config *Config) validate() error {
+	if config.WordCount <= 0 {
+		return errors.New("The word count of a passphrase cannot be zero or negative")
+	}
+	if config.Wordlist.words() == nil {
+		return errors.New("The wordlist of a passphrase is unknown, verify the configuration")
+	}
+	if config.Separator == "" {
+		// Without a separator the words could be told apart in more than one way, so the passphrases
+		// would be fewer than Keyspace counts
+		return errors.New("The separator of a passphrase cannot be empty")
+	}
+	if config.InsertDigit && len(removeRunesIn(digitRunes, config.Separator)) == 0 {
+		return errors.New("The separator of a passphrase has every digit, so no digit can be inserted")
+	}
+	if config.InsertSpecial && len(removeRunesIn(specialRunes, config.Separator)) == 0 {
+		return errors.New("The separator of a passphrase has every special symbol, so no special symbol can be inserted")
+	}
+	return nil
+}
+
+func (list Wordlist) words() []string {
+	switch list {
+	case EFFLarge:
+		return effLargeWords
+	case EFFShort:
+		return effShortWords
+	default:
+		return nil
+	}
+}
+
+func withoutHyphenatedWords(words []string) []string {
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		if !strings.ContainsRune(word, '-') {
+			result = append(result, word)
+		}
+	}
+	return result
+}
+
+func removeRunesIn(runes []rune, removed string) []rune {
+	result := make([]rune, 0, len(runes))
+	for _, r := range runes {
+		if !strings.ContainsRune(removed, r) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func capitalizeWord(word string) string {
+	r, size := utf8.DecodeRuneInString(word)
+	return string(unicode.ToUpper(r)) + word[size:]
+}
+
+func insertRandomRune(words []string, runes []rune) error {
+	wordIndex, err := rand.IntnE(len(words))
+	if err != nil {
+		return err
+	}
+	wordRunes := []rune(words[wordIndex])
+	position, err := rand.IntnE(len(wordRunes) + 1)
+	if err != nil {
+		return err
+	}
+	runeIndex, err := rand.IntnE(len(runes))
+	if err != nil {
+		return err
+	}
+
+	result := make([]rune, 0, len(wordRunes)+1)
+	result = append(result, wordRunes[:position]...)
+	result = append(result, runes[runeIndex])
+	result = append(result, wordRunes[position:]...)
+	words[wordIndex] = string(result)
+	return nil
+}
@@ -0,0 +1,195 @@
+package passphrase_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/passphrase"
+)
+
+func TestNewWithoutConfigShouldSucceed(t *testing.T) {
+	generator, err := passphrase.New(nil)
+	assert.NoError(t, err)
+	assert.NotNil(t, generator)
+}
+
+func TestNewWithZeroWordCountShouldFail(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:  passphrase.EFFLarge,
+		WordCount: 0,
+	}
+	generator, err := passphrase.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "word count")
+}
+
+func TestNewWithUnknownWordlistShouldFail(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:  passphrase.Wordlist(42),
+		WordCount: 6,
+	}
+	generator, err := passphrase.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "wordlist")
+}
+
+func TestParseWordlistShouldSucceed(t *testing.T) {
+	wordlist, err := passphrase.ParseWordlist("large")
+	assert.NoError(t, err)
+	assert.Equal(t, passphrase.EFFLarge, wordlist)
+
+	wordlist, err = passphrase.ParseWordlist("Short")
+	assert.NoError(t, err)
+	assert.Equal(t, passphrase.EFFShort, wordlist)
+}
+
+func TestParseWordlistWithUnknownNameShouldFail(t *testing.T) {
+	_, err := passphrase.ParseWordlist("klingon")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Unknown wordlist")
+}
+
+func TestGeneratePassphraseDefaultShouldSucceed(t *testing.T) {
+	generator, _ := passphrase.New(nil)
+	phrase := generator.GeneratePassphrase()
+	assert.NotEmpty(t, phrase)
+	assert.Len(t, strings.Split(phrase, "-"), 6)
+}
+
+func TestGeneratePassphraseShouldSplitBySeparator(t *testing.T) {
+	for _, wordlist := range []passphrase.Wordlist{passphrase.EFFLarge, passphrase.EFFShort} {
+		generatorConfig := passphrase.Config{
+			Wordlist:      wordlist,
+			WordCount:     6,
+			Separator:     "-",
+			InsertDigit:   true,
+			InsertSpecial: true,
+		}
+		generator, _ := passphrase.New(&generatorConfig)
+
+		// The hyphenated words and a hyphen as the special symbol would add words
+		for i := 0; i < 1000; i++ {
+			phrase := generator.GeneratePassphrase()
+			assert.Len(t, strings.Split(phrase, "-"), generatorConfig.WordCount, phrase)
+		}
+	}
+}
+
+func TestNewWithDigitSeparatorAndDigitShouldFail(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:    passphrase.EFFShort,
+		WordCount:   4,
+		Separator:   "0123456789",
+		InsertDigit: true,
+	}
+	generator, err := passphrase.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+}
+
+func TestNewWithSpecialSeparatorAndSpecialShouldFail(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:      passphrase.EFFLarge,
+		WordCount:     6,
+		Separator:     "!#%&()*+-./:;=?@[]_{}~",
+		InsertSpecial: true,
+	}
+	generator, err := passphrase.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "special symbol")
+}
+
+func TestNewWithEmptySeparatorShouldFail(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:  passphrase.EFFLarge,
+		WordCount: 6,
+	}
+	generator, err := passphrase.New(&generatorConfig)
+	assert.Error(t, err)
+	assert.Nil(t, generator)
+	assert.Contains(t, err.Error(), "separator")
+}
+
+func TestGeneratePassphraseWithSeparatorShouldSucceed(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:  passphrase.EFFShort,
+		WordCount: 4,
+		Separator: " ",
+	}
+	generator, _ := passphrase.New(&generatorConfig)
+
+	for i := 0; i < 100; i++ {
+		words := strings.Split(generator.GeneratePassphrase(), " ")
+		assert.Len(t, words, 4)
+		for _, word := range words {
+			assert.NotEmpty(t, word)
+			assert.Equal(t, strings.ToLower(word), word)
+		}
+	}
+}
+
+func TestGeneratePassphraseWithCapitalizeShouldSucceed(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:   passphrase.EFFLarge,
+		WordCount:  8,
+		Separator:  " ",
+		Capitalize: true,
+	}
+	generator, _ := passphrase.New(&generatorConfig)
+
+	foundUpper := false
+	for i := 0; i < 100 && !foundUpper; i++ {
+		for _, r := range generator.GeneratePassphrase() {
+			if unicode.IsUpper(r) {
+				foundUpper = true
+				break
+			}
+		}
+	}
+	assert.True(t, foundUpper)
+}
+
+func TestGeneratePassphraseWithDigitAndSpecialShouldSucceed(t *testing.T) {
+	generatorConfig := passphrase.Config{
+		Wordlist:      passphrase.EFFLarge,
+		WordCount:     5,
+		Separator:     " ",
+		InsertDigit:   true,
+		InsertSpecial: true,
+	}
+	generator, _ := passphrase.New(&generatorConfig)
+
+	for i := 0; i < 100; i++ {
+		phrase := generator.GeneratePassphrase()
+		assert.Len(t, strings.Split(phrase, " "), 5)
+		assert.True(t, strings.IndexFunc(phrase, unicode.IsDigit) >= 0, phrase)
+
+		insertedCount := 0
+		for _, r := range phrase {
+			if r != ' ' && !unicode.IsLetter(r) {
+				insertedCount++
+			}
+		}
+		assert.GreaterOrEqual(t, insertedCount, 2, phrase)
+	}
+}
+
+func TestKeyspaceShouldCountWordsAndCapitalization(t *testing.T) {
+	generator, err := passphrase.New(&passphrase.Config{
+		Wordlist:   passphrase.EFFShort,
+		WordCount:  2,
+		Separator:  " ",
+		Capitalize: true,
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "6708100", generator.Keyspace().String())
+	assert.InDelta(t, 2*math.Log2(2590), generator.Entropy(), 1e-9)
+}
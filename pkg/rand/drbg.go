@@ -0,0 +1,150 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package rand
+
+import (
+	crand "crypto/rand"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20"
+)
+
+const (
+	// drbgKeySize is the size of the ChaCha20 key, which is also the size of a seed
+	drbgKeySize = chacha20.KeySize
+	// drbgBufferSize is the size of the keystream generated at a time, which includes the next key
+	drbgBufferSize = 768
+	// drbgReseedInterval is how many bytes a system-seeded generator outputs before it is reseeded
+	drbgReseedInterval = 1 << 20
+)
+
+// DRBG is a deterministic random bit generator based on ChaCha20 with fast key erasure. Each
+// refill generates a block of keystream from the current key, where the first bytes immediately
+// replace the key and the rest is output, and output bytes are erased as soon as they are used.
+// A compromised state therefore reveals nothing about earlier output.
+//
+// A DRBG is not safe for concurrent use by multiple goroutines.
+type DRBG struct {
+	key      [drbgKeySize]byte
+	buffer   [drbgBufferSize]byte
+	position int
+
+	// System-seeded generators are reseeded from the operating system periodically and when
+	// the process has forked, where pid is the process that the state belongs to
+	systemSeeded bool
+	pid          int
+	sinceReseed  int
+}
+
+// NewDRBG returns a new generator with the specified seed, which must be 32 bytes. The output
+// only depends on the seed, so the seed must be secret and never reused.
+func NewDRBG(seed []byte) (*DRBG, error) {
+	if len(seed) != drbgKeySize {
+		return nil, errors.Errorf("The seed must be %d bytes", drbgKeySize)
+	}
+
+	d := &DRBG{}
+	copy(d.key[:], seed)
+	d.position = drbgBufferSize
+	return d, nil
+}
+
+// newSystemDRBG returns a new generator seeded from the operating system.
+func newSystemDRBG() (*DRBG, error) {
+	d := &DRBG{systemSeeded: true}
+	d.position = drbgBufferSize
+	if err := d.reseed(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Read fills p with random bytes. It only returns an error if the generator is seeded from the
+// operating system and reseeding fails.
+func (d *DRBG) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if d.position == drbgBufferSize {
+			if err := d.refill(); err != nil {
+				return n, err
+			}
+		}
+
+		copied := copy(p[n:], d.buffer[d.position:])
+		erase(d.buffer[d.position : d.position+copied])
+		d.position += copied
+		n += copied
+	}
+	return n, nil
+}
+
+func (d *DRBG) refill() error {
+	if d.systemSeeded && (d.sinceReseed >= drbgReseedInterval || d.pid != os.Getpid()) {
+		if err := d.reseed(); err != nil {
+			return err
+		}
+	}
+
+	// The key changes with every refill, so a fixed nonce is never used twice with the same key
+	var nonce [chacha20.NonceSize]byte
+	cipher, err := chacha20.NewUnauthenticatedCipher(d.key[:], nonce[:])
+	if err != nil {
+		panic(err)
+	}
+	erase(d.buffer[:])
+	cipher.XORKeyStream(d.buffer[:], d.buffer[:])
+
+	copy(d.key[:], d.buffer[:drbgKeySize])
+	erase(d.buffer[:drbgKeySize])
+	d.position = drbgKeySize
+	d.sinceReseed += drbgBufferSize - drbgKeySize
+	return nil
+}
+
+// reseed mixes fresh entropy from the operating system into the key and discards buffered output.
+func (d *DRBG) reseed() error {
+	var seed [drbgKeySize]byte
+	if _, err := io.ReadFull(crand.Reader, seed[:]); err != nil {
+		return errors.Wrap(err, "Failed to read from the random source of the operating system")
+	}
+	for i := range d.key {
+		d.key[i] ^= seed[i]
+	}
+	erase(seed[:])
+
+	erase(d.buffer[:])
+	d.position = drbgBufferSize
+	d.pid = os.Getpid()
+	d.sinceReseed = 0
+	return nil
+}
+
+func erase(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -24,40 +24,175 @@ SOFTWARE.
 
 // Credits: https://web.archive.org/web/20190622035629/https://yourbasic.org/golang/crypto-rand-int/
 
+// Package rand provides cryptographically secure random numbers. All functions are safe for
+// concurrent use by multiple goroutines. The functions without the E suffix panic if the
+// random source of the operating system fails, while the E variants return the error.
 package rand
 
 import (
-	crand "crypto/rand"
 	"encoding/binary"
-	"log"
+	"io"
+	"math"
+	"math/big"
 	rand "math/rand"
+	"sync"
+
+	"github.com/pkg/errors"
 )
 
+// Source draws random numbers from a reader of random bytes. It is safe for concurrent use if
+// its reader is. The numbers only depend on the bytes that are read, so a Source with a
+// deterministic reader, such as a DRBG with a fixed seed, draws the same numbers every time.
+type Source struct {
+	reader io.Reader
+}
+
+// defaultSource reads from generators seeded by the operating system.
+var defaultSource = NewSource(pooledReader{})
+
+// NewSource returns a new source that reads random bytes from the specified reader. If reader
+// is nil, the source reads from generators seeded by the operating system.
+func NewSource(reader io.Reader) *Source {
+	if reader == nil {
+		reader = pooledReader{}
+	}
+	return &Source{reader: reader}
+}
+
+// Default returns the source that the package functions use, which reads from generators
+// seeded by the operating system.
+func Default() *Source {
+	return defaultSource
+}
+
+// IntnE returns, as an int, a non-negative random number in [0,n), or an error if the reader
+// fails. It panics if n <= 0.
+func (s *Source) IntnE(n int) (int, error) {
+	return intn(s.reader, n)
+}
+
+// BigIntnE returns a uniform random number in [0,n), or an error if the reader fails.
+// It panics if n <= 0.
+func (s *Source) BigIntnE(n *big.Int) (*big.Int, error) {
+	return bigIntn(s.reader, n)
+}
+
+// WeightedIntnE returns a random index into weights, with a probability proportional to its
+// weight, or an error if the reader fails. It panics if the weights do not add up to more than zero.
+func (s *Source) WeightedIntnE(weights []*big.Int) (int, error) {
+	return weightedIntn(s.reader, weights)
+}
+
+// ShuffleE randomizes the order of elements, or returns an error if the reader fails.
+// It panics if n < 0.
+func (s *Source) ShuffleE(n int, swap func(i, j int)) error {
+	return shuffle(s.reader, n, swap)
+}
+
+// cryptoSource is stateless, which makes the Rand methods that only draw from the source safe for
+// concurrent use. Only Rand.Read keeps state of its own.
 type cryptoSource struct{}
 
-var globalRand = New()
+// drbgPool holds generators seeded from the operating system, since a system call for every
+// number would be a bottleneck. A generator is only used by one goroutine at a time.
+var drbgPool = sync.Pool{
+	New: func() interface{} {
+		d, err := newSystemDRBG()
+		if err != nil {
+			// The error is reported when the generator is taken from the pool
+			return nil
+		}
+		return d
+	},
+}
 
-// New returns a new cryptographically secure pseudo-random number generator.
+// New returns a new cryptographically secure pseudo-random number generator. Its Read method is
+// not safe for concurrent use, while all other methods are. It panics if the random source of
+// the operating system fails.
 func New() *rand.Rand {
 	return rand.New(&cryptoSource{})
 }
 
+// Read fills p with random bytes from generators seeded by the operating system. It returns
+// an error if the random source of the operating system fails.
+func Read(p []byte) (int, error) {
+	return io.ReadFull(pooledReader{}, p)
+}
+
 // Int returns a non-negative pseudo-random int.
 func Int() int {
-	return globalRand.Int()
+	v, err := IntE()
+	mustSucceed(err)
+	return v
+}
+
+// IntE returns a non-negative pseudo-random int, or an error if the random source fails.
+func IntE() (int, error) {
+	v, err := uint64E(pooledReader{})
+	return int(uint(v) << 1 >> 1), err
 }
 
 // Intn returns, as an int, a non-negative pseudo-random number in [0,n).
 // It panics if n <= 0.
 func Intn(n int) int {
-	return globalRand.Intn(n)
+	v, err := IntnE(n)
+	mustSucceed(err)
+	return v
+}
+
+// IntnE returns, as an int, a non-negative pseudo-random number in [0,n), or an error if the
+// random source fails. It panics if n <= 0.
+func IntnE(n int) (int, error) {
+	return defaultSource.IntnE(n)
+}
+
+// BigIntn returns a uniform random number in [0,n).
+// It panics if n <= 0.
+func BigIntn(n *big.Int) *big.Int {
+	v, err := BigIntnE(n)
+	mustSucceed(err)
+	return v
+}
+
+// BigIntnE returns a uniform random number in [0,n), or an error if the random source fails.
+// It panics if n <= 0.
+func BigIntnE(n *big.Int) (*big.Int, error) {
+	return defaultSource.BigIntnE(n)
+}
+
+// WeightedIntnE returns a random index into weights, with a probability proportional to its weight,
+// or an error if the random source fails. It panics if the weights do not add up to more than zero.
+func WeightedIntnE(weights []*big.Int) (int, error) {
+	return defaultSource.WeightedIntnE(weights)
+}
+
+// Entropy returns the entropy in bits of a uniformly random choice from n possibilities, which
+// is the base-2 logarithm of n. It returns zero if n is zero or negative.
+func Entropy(n *big.Int) float64 {
+	if n.Sign() <= 0 {
+		return 0
+	}
+
+	// Keep the 53 most significant bits, which is all a float64 can represent
+	shift := n.BitLen() - 53
+	if shift <= 0 {
+		return math.Log2(float64(n.Int64()))
+	}
+	mantissa := new(big.Int).Rsh(n, uint(shift))
+	return math.Log2(float64(mantissa.Int64())) + float64(shift)
 }
 
 // Shuffle pseudo-randomizes the order of elements.
 // n is the number of elements. Shuffle panics if n < 0.
 // swap swaps the elements with indexes i and j.
 func Shuffle(n int, swap func(i, j int)) {
-	globalRand.Shuffle(n, swap)
+	mustSucceed(ShuffleE(n, swap))
+}
+
+// ShuffleE pseudo-randomizes the order of elements, or returns an error if the random source
+// fails, in which case the elements may be partially shuffled. It panics if n < 0.
+func ShuffleE(n int, swap func(i, j int)) error {
+	return defaultSource.ShuffleE(n, swap)
 }
 
 func (s *cryptoSource) Seed(seed int64) {}
@@ -66,10 +201,127 @@ func (s *cryptoSource) Int63() int64 {
 	return int64(s.Uint64() &^ uint64(1<<63))
 }
 
-func (s *cryptoSource) Uint64() (v uint64) {
-	err := binary.Read(crand.Reader, binary.BigEndian, &v)
+func (s *cryptoSource) Uint64() uint64 {
+	v, err := uint64E(pooledReader{})
+	mustSucceed(err)
+	return v
+}
+
+// pooledReader reads random bytes from the pooled generators.
+type pooledReader struct{}
+
+func (pooledReader) Read(p []byte) (int, error) {
+	d, ok := drbgPool.Get().(*DRBG)
+	if !ok {
+		// Creating the generator again reports why the pool could not create one
+		var err error
+		if d, err = newSystemDRBG(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := d.Read(p)
+	if err == nil {
+		drbgPool.Put(d)
+	}
+	return n, err
+}
+
+func uint64E(r io.Reader) (uint64, error) {
+	var buffer [8]byte
+	if _, err := io.ReadFull(r, buffer[:]); err != nil {
+		return 0, errors.Wrap(err, "Failed to read random bytes")
+	}
+	return binary.BigEndian.Uint64(buffer[:]), nil
+}
+
+// intn returns a uniform random number in [0,n), where values at the end of the 64-bit range
+// that would bias the result are rejected.
+func intn(r io.Reader, n int) (int, error) {
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+
+	max := uint64(n)
+	threshold := -max % max
+	for {
+		v, err := uint64E(r)
+		if err != nil {
+			return 0, err
+		}
+		if v >= threshold {
+			return int(v % max), nil
+		}
+	}
+}
+
+// bigIntn returns a uniform random number in [0,n), where random numbers with as many bits as
+// n - 1 are rejected until one is lower than n.
+func bigIntn(r io.Reader, n *big.Int) (*big.Int, error) {
+	if n.Sign() <= 0 {
+		panic("invalid argument to BigIntn")
+	}
+
+	max := new(big.Int).Sub(n, big.NewInt(1))
+	bitLen := max.BitLen()
+	if bitLen == 0 {
+		return new(big.Int), nil
+	}
+
+	buffer := make([]byte, (bitLen+7)/8)
+	v := new(big.Int)
+	for {
+		if _, err := io.ReadFull(r, buffer); err != nil {
+			return nil, errors.Wrap(err, "Failed to read random bytes")
+		}
+		// Clear the bits above the bit length of the maximum
+		buffer[0] &= byte(1<<uint(8-(len(buffer)*8-bitLen)) - 1)
+		v.SetBytes(buffer)
+		if v.Cmp(n) < 0 {
+			return v, nil
+		}
+	}
+}
+
+// weightedIntn draws a uniform random number below the total weight, and returns the index of
+// the weight that it falls into.
+func weightedIntn(r io.Reader, weights []*big.Int) (int, error) {
+	total := new(big.Int)
+	for _, weight := range weights {
+		total.Add(total, weight)
+	}
+
+	value, err := bigIntn(r, total)
 	if err != nil {
-		log.Fatal(err)
+		return 0, err
+	}
+	for i, weight := range weights {
+		if value.Cmp(weight) < 0 {
+			return i, nil
+		}
+		value.Sub(value, weight)
+	}
+	panic("the weighted value is out of range")
+}
+
+// shuffle is the Fisher-Yates shuffle.
+func shuffle(r io.Reader, n int, swap func(i, j int)) error {
+	if n < 0 {
+		panic("invalid argument to Shuffle")
+	}
+
+	for i := n - 1; i > 0; i-- {
+		j, err := intn(r, i+1)
+		if err != nil {
+			return err
+		}
+		swap(i, j)
+	}
+	return nil
+}
+
+func mustSucceed(err error) {
+	if err != nil {
+		panic(err)
 	}
-	return v
 }
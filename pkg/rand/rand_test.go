@@ -0,0 +1,182 @@
+package rand_test
+
+import (
+	"bytes"
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+func TestDRBGKnownAnswerShouldSucceed(t *testing.T) {
+	d, err := rand.NewDRBG(make([]byte, 32))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The ChaCha20 keystream of the all-zero key and nonce from RFC 8439, appendix A.1, where
+	// the first 32 bytes of the first block become the next key instead of output
+	output := make([]byte, 32+64)
+	d.Read(output)
+	assert.Equal(t, "da41597c5157488d7724e03fb8d84a376a43b8f41518a11cc387b669b2ee6586", hex.EncodeToString(output[:32]))
+	assert.Equal(t, "9f07e7be5551387a98ba977c732d080dcb0f29a048e3656912c6533e32ee7aed"+
+		"29b721769ce64e43d57133b074d839d531ed1f28510afb45ace10a1f4b794d6f", hex.EncodeToString(output[32:]))
+}
+
+func TestDRBGShouldBeDeterministic(t *testing.T) {
+	seed := []byte("0123456789abcdef0123456789abcdef")
+	first, _ := rand.NewDRBG(seed)
+	second, _ := rand.NewDRBG(seed)
+
+	// Read past several refills, in different chunk sizes
+	firstOutput := make([]byte, 5000)
+	first.Read(firstOutput)
+	secondOutput := make([]byte, 0, 5000)
+	for len(secondOutput) < 5000 {
+		chunk := make([]byte, 7)
+		second.Read(chunk)
+		secondOutput = append(secondOutput, chunk...)
+	}
+	assert.Equal(t, firstOutput, secondOutput[:5000])
+}
+
+func TestNewDRBGWithInvalidSeedShouldFail(t *testing.T) {
+	_, err := rand.NewDRBG(make([]byte, 16))
+	assert.Error(t, err)
+}
+
+func TestIntnShouldSucceed(t *testing.T) {
+	seen := make(map[int]bool)
+	for i := 0; i < 1000; i++ {
+		value := rand.Intn(10)
+		assert.True(t, value >= 0 && value < 10)
+		seen[value] = true
+	}
+	assert.Len(t, seen, 10)
+}
+
+func TestBigIntnShouldSucceed(t *testing.T) {
+	n := new(big.Int).Lsh(big.NewInt(1), 100)
+	for i := 0; i < 100; i++ {
+		value := rand.BigIntn(n)
+		assert.True(t, value.Sign() >= 0 && value.Cmp(n) < 0)
+	}
+}
+
+func TestWeightedIntnShouldSucceed(t *testing.T) {
+	weights := []*big.Int{big.NewInt(0), big.NewInt(3), big.NewInt(0), big.NewInt(1)}
+	seen := make(map[int]bool)
+	for i := 0; i < 100; i++ {
+		index, err := rand.WeightedIntnE(weights)
+		assert.NoError(t, err)
+		assert.True(t, index == 1 || index == 3, index)
+		seen[index] = true
+	}
+	assert.Len(t, seen, 2)
+}
+
+func TestEntropyShouldSucceed(t *testing.T) {
+	assert.Equal(t, 0.0, rand.Entropy(big.NewInt(0)))
+	assert.Equal(t, 0.0, rand.Entropy(big.NewInt(1)))
+	assert.InDelta(t, 10.0, rand.Entropy(big.NewInt(1024)), 1e-12)
+	assert.InDelta(t, 200.0, rand.Entropy(new(big.Int).Lsh(big.NewInt(1), 200)), 1e-12)
+}
+
+func TestShuffleShouldSucceed(t *testing.T) {
+	values := []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	rand.Shuffle(len(values), func(i, j int) {
+		values[i], values[j] = values[j], values[i]
+	})
+	sort.Ints(values)
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, values)
+}
+
+func BenchmarkInt(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		rand.Int()
+	}
+}
+
+func BenchmarkIntConcurrently(b *testing.B) {
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			rand.Int()
+		}
+	})
+}
+
+// BenchmarkCryptoRandBinaryRead is the previous source, which read every number from the operating system.
+func BenchmarkCryptoRandBinaryRead(b *testing.B) {
+	var v uint64
+	for i := 0; i < b.N; i++ {
+		binary.Read(crand.Reader, binary.BigEndian, &v)
+	}
+}
+
+func BenchmarkDRBGRead(b *testing.B) {
+	d, _ := rand.NewDRBG(make([]byte, 32))
+	buffer := make([]byte, 1024)
+	b.SetBytes(int64(len(buffer)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		d.Read(buffer)
+	}
+}
+
+func TestErrorReturningFunctionsShouldSucceed(t *testing.T) {
+	value, err := rand.IntnE(10)
+	assert.NoError(t, err)
+	assert.True(t, value >= 0 && value < 10)
+
+	value, err = rand.IntE()
+	assert.NoError(t, err)
+	assert.True(t, value >= 0)
+
+	bigValue, err := rand.BigIntnE(big.NewInt(10))
+	assert.NoError(t, err)
+	assert.True(t, bigValue.Sign() >= 0 && bigValue.Cmp(big.NewInt(10)) < 0)
+
+	values := []int{0, 1, 2}
+	assert.NoError(t, rand.ShuffleE(len(values), func(i, j int) {
+		values[i], values[j] = values[j], values[i]
+	}))
+	assert.ElementsMatch(t, []int{0, 1, 2}, values)
+}
+
+func TestSourceWithSeedShouldBeDeterministic(t *testing.T) {
+	seed := []byte("0123456789abcdef0123456789abcdef")
+	firstReader, _ := rand.NewDRBG(seed)
+	secondReader, _ := rand.NewDRBG(seed)
+	first := rand.NewSource(firstReader)
+	second := rand.NewSource(secondReader)
+
+	n := new(big.Int).Lsh(big.NewInt(1), 70)
+	for i := 0; i < 100; i++ {
+		firstValue, err := first.IntnE(1000)
+		assert.NoError(t, err)
+		secondValue, _ := second.IntnE(1000)
+		assert.Equal(t, firstValue, secondValue)
+
+		firstBig, err := first.BigIntnE(n)
+		assert.NoError(t, err)
+		secondBig, _ := second.BigIntnE(n)
+		assert.Equal(t, firstBig, secondBig)
+		assert.True(t, firstBig.Cmp(n) < 0)
+	}
+}
+
+func TestSourceWithFailingReaderShouldFail(t *testing.T) {
+	source := rand.NewSource(bytes.NewReader(nil))
+	_, err := source.IntnE(10)
+	assert.Error(t, err)
+	_, err = source.BigIntnE(big.NewInt(10))
+	assert.Error(t, err)
+	assert.Error(t, source.ShuffleE(3, func(i, j int) {}))
+	_, err = source.WeightedIntnE([]*big.Int{big.NewInt(1), big.NewInt(2)})
+	assert.Error(t, err)
+}
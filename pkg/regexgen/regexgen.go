@@ -0,0 +1,402 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package regexgen generates random strings that match a regular expression.
+package regexgen
+
+import (
+	"math/big"
+	"regexp/syntax"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+// Generator generates random strings that match a regular expression. A Generator is safe for
+// concurrent use by multiple goroutines, since everything it looks up is computed by New.
+type Generator struct {
+	regexp    *syntax.Regexp
+	alphabet  map[rune]bool
+	anyRunes  []rune
+	maxRepeat int
+	counts    map[*syntax.Regexp]*big.Int
+	runes     map[*syntax.Regexp][]rune
+}
+
+// Config is the regular expression generator configuration.
+type Config struct {
+	// MaxRepeat caps the unbounded quantifiers *, + and {n,}, which repeat at most this many times.
+	MaxRepeat int
+
+	// Alphabet is the characters that . and character classes are drawn from. A character class
+	// without any of these characters, such as \p{Greek}, uses its own printable characters instead.
+	Alphabet []rune
+}
+
+// New returns a new generator of strings that match the pattern, which uses the Go regular
+// expression syntax. If config is nil, the default configuration is used.
+//
+// Strings are drawn uniformly from the ways the pattern can match, so for patterns where every
+// string matches in only one way, such as [a-z]{8}|[0-9]{4}, every string is equally likely.
+func New(pattern string, config *Config) (*Generator, error) {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	if config.MaxRepeat < 0 {
+		return nil, errors.New("The maximum repeat of a pattern cannot be negative")
+	}
+	if len(config.Alphabet) == 0 {
+		return nil, errors.New("The alphabet of a pattern cannot be empty")
+	}
+
+	regexp, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to parse the pattern")
+	}
+
+	if err := validateAnchors(regexp, true, true); err != nil {
+		return nil, err
+	}
+
+	gen := &Generator{
+		regexp:    regexp,
+		alphabet:  make(map[rune]bool, len(config.Alphabet)),
+		maxRepeat: config.MaxRepeat,
+		counts:    make(map[*syntax.Regexp]*big.Int),
+		runes:     make(map[*syntax.Regexp][]rune),
+	}
+	for _, r := range config.Alphabet {
+		if !gen.alphabet[r] {
+			gen.alphabet[r] = true
+			gen.anyRunes = append(gen.anyRunes, r)
+		}
+	}
+
+	count, err := gen.count(regexp)
+	if err != nil {
+		return nil, err
+	}
+	if count.Sign() == 0 {
+		return nil, errors.New("The pattern does not match any string within the alphabet")
+	}
+	return gen, nil
+}
+
+// DefaultConfig returns the default configuration, with the printable ASCII characters as the alphabet.
+func DefaultConfig() *Config {
+	alphabet := make([]rune, 0, 0x7f-0x20)
+	for r := rune(0x20); r < 0x7f; r++ {
+		alphabet = append(alphabet, r)
+	}
+	return &Config{
+		MaxRepeat: 16,
+		Alphabet:  alphabet,
+	}
+}
+
+// Keyspace returns the number of ways the pattern can match, which is the number of distinct
+// strings if every string matches in only one way.
+func (gen *Generator) Keyspace() *big.Int {
+	count, _ := gen.count(gen.regexp)
+	return new(big.Int).Set(count)
+}
+
+// Entropy returns the entropy of the pattern in bits, which is the base-2 logarithm of the keyspace.
+func (gen *Generator) Entropy() float64 {
+	return rand.Entropy(gen.Keyspace())
+}
+
+// Generate generates a random string that matches the pattern. It panics if the random source
+// fails, see GenerateE.
+func (gen *Generator) Generate() string {
+	value, err := gen.GenerateE()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// GenerateE generates a random string that matches the pattern, or returns an error if the random source fails.
+func (gen *Generator) GenerateE() (string, error) {
+	value, err := gen.generate(nil, gen.regexp)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to generate a string")
+	}
+	return string(value), nil
+}
+
+// count returns the number of ways the expression can match.
+func (gen *Generator) count(re *syntax.Regexp) (*big.Int, error) {
+	if count, ok := gen.counts[re]; ok {
+		return count, nil
+	}
+
+	count := new(big.Int)
+	switch re.Op {
+	case syntax.OpNoMatch:
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		// Anchors match the empty string, since the generated string is the whole match
+		count.SetInt64(1)
+	case syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return nil, errors.New("Word boundaries are not supported in patterns")
+	case syntax.OpLiteral:
+		count.SetInt64(1)
+		for _, r := range re.Rune {
+			count.Mul(count, big.NewInt(int64(len(gen.literalRunes(re, r)))))
+		}
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		count.SetInt64(int64(len(gen.classRunes(re))))
+	case syntax.OpCapture:
+		return gen.memoize(re, re.Sub[0])
+	case syntax.OpConcat:
+		count.SetInt64(1)
+		for _, sub := range re.Sub {
+			subCount, err := gen.count(sub)
+			if err != nil {
+				return nil, err
+			}
+			count.Mul(count, subCount)
+		}
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			subCount, err := gen.count(sub)
+			if err != nil {
+				return nil, err
+			}
+			count.Add(count, subCount)
+		}
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		subCount, err := gen.count(re.Sub[0])
+		if err != nil {
+			return nil, err
+		}
+		for _, weight := range gen.repeatWeights(re, subCount) {
+			count.Add(count, weight)
+		}
+	default:
+		return nil, errors.Errorf("Unsupported pattern operation: %s", re.Op)
+	}
+
+	gen.counts[re] = count
+	return count, nil
+}
+
+// validateAnchors returns an error if an anchor can be preceded or followed by other characters,
+// such as in a$b, since anchors are generated as empty matches of the whole string.
+func validateAnchors(re *syntax.Regexp, atStart, atEnd bool) error {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpBeginText:
+		if !atStart {
+			return errors.New("Anchors for the beginning are only supported at the start of a pattern")
+		}
+	case syntax.OpEndLine, syntax.OpEndText:
+		if !atEnd {
+			return errors.New("Anchors for the end are only supported at the end of a pattern")
+		}
+	case syntax.OpConcat:
+		// Only empty matches, such as other anchors, can come before or after an anchor
+		for i, sub := range re.Sub {
+			subAtStart := atStart && allEmptyWidth(re.Sub[:i])
+			subAtEnd := atEnd && allEmptyWidth(re.Sub[i+1:])
+			if err := validateAnchors(sub, subAtStart, subAtEnd); err != nil {
+				return err
+			}
+		}
+	case syntax.OpCapture, syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if err := validateAnchors(sub, atStart, atEnd); err != nil {
+				return err
+			}
+		}
+	default:
+		// A repeated expression is preceded or followed by its other repeats
+		for _, sub := range re.Sub {
+			if err := validateAnchors(sub, false, false); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func allEmptyWidth(res []*syntax.Regexp) bool {
+	for _, re := range res {
+		switch re.Op {
+		case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (gen *Generator) memoize(re, sub *syntax.Regexp) (*big.Int, error) {
+	count, err := gen.count(sub)
+	if err != nil {
+		return nil, err
+	}
+	gen.counts[re] = count
+	return count, nil
+}
+
+func (gen *Generator) generate(buffer []rune, re *syntax.Regexp) ([]rune, error) {
+	var err error
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if buffer, err = appendRandomRune(buffer, gen.literalRunes(re, r)); err != nil {
+				return nil, err
+			}
+		}
+	case syntax.OpCharClass, syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		buffer, err = appendRandomRune(buffer, gen.classRunes(re))
+	case syntax.OpCapture:
+		buffer, err = gen.generate(buffer, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if buffer, err = gen.generate(buffer, sub); err != nil {
+				return nil, err
+			}
+		}
+	case syntax.OpAlternate:
+		weights := make([]*big.Int, len(re.Sub))
+		for i, sub := range re.Sub {
+			weights[i] = gen.counts[sub]
+		}
+		var index int
+		if index, err = rand.WeightedIntnE(weights); err != nil {
+			return nil, err
+		}
+		buffer, err = gen.generate(buffer, re.Sub[index])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, _ := gen.repeatBounds(re)
+		var index int
+		if index, err = rand.WeightedIntnE(gen.repeatWeights(re, gen.counts[re.Sub[0]])); err != nil {
+			return nil, err
+		}
+		for i := 0; i < min+index; i++ {
+			if buffer, err = gen.generate(buffer, re.Sub[0]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return buffer, err
+}
+
+// repeatBounds returns the minimum and maximum number of repeats, where unbounded repeats are capped.
+func (gen *Generator) repeatBounds(re *syntax.Regexp) (int, int) {
+	min, max := 0, -1
+	switch re.Op {
+	case syntax.OpPlus:
+		min = 1
+	case syntax.OpQuest:
+		max = 1
+	case syntax.OpRepeat:
+		min, max = re.Min, re.Max
+	}
+
+	if max < 0 {
+		max = gen.maxRepeat
+		if max < min {
+			max = min
+		}
+	}
+	return min, max
+}
+
+// repeatWeights returns the number of ways the expression can match for each number of repeats.
+func (gen *Generator) repeatWeights(re *syntax.Regexp, subCount *big.Int) []*big.Int {
+	min, max := gen.repeatBounds(re)
+	weights := make([]*big.Int, 0, max-min+1)
+	for repeat := min; repeat <= max; repeat++ {
+		weights = append(weights, new(big.Int).Exp(subCount, big.NewInt(int64(repeat)), nil))
+	}
+	return weights
+}
+
+// classRunes returns the characters of a character class within the alphabet, or the printable
+// characters of the class if it has none within the alphabet.
+func (gen *Generator) classRunes(re *syntax.Regexp) []rune {
+	if runes, ok := gen.runes[re]; ok {
+		return runes
+	}
+
+	var runes []rune
+	switch re.Op {
+	case syntax.OpAnyChar:
+		runes = gen.anyRunes
+	case syntax.OpAnyCharNotNL:
+		for _, r := range gen.anyRunes {
+			if r != '\n' {
+				runes = append(runes, r)
+			}
+		}
+	default:
+		for i := 0; i < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if gen.alphabet[r] {
+					runes = append(runes, r)
+				}
+			}
+		}
+		if len(runes) == 0 {
+			for i := 0; i < len(re.Rune); i += 2 {
+				for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+					if unicode.IsPrint(r) {
+						runes = append(runes, r)
+					}
+				}
+			}
+		}
+	}
+
+	gen.runes[re] = runes
+	return runes
+}
+
+// literalRunes returns the characters that match a literal character, which are also its other
+// cases within the alphabet if the literal is case-insensitive. Other cases outside the alphabet,
+// such as the Kelvin sign for k, are left out.
+func (gen *Generator) literalRunes(re *syntax.Regexp, r rune) []rune {
+	if re.Flags&syntax.FoldCase == 0 {
+		return []rune{r}
+	}
+
+	runes := []rune{r}
+	for fold := unicode.SimpleFold(r); fold != r; fold = unicode.SimpleFold(fold) {
+		if gen.alphabet[fold] {
+			runes = append(runes, fold)
+		}
+	}
+	return runes
+}
+
+func appendRandomRune(buffer []rune, runes []rune) ([]rune, error) {
+	index, err := rand.IntnE(len(runes))
+	if err != nil {
+		return nil, err
+	}
+	return append(buffer, runes[index]), nil
+}
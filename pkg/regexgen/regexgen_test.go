@@ -0,0 +1,107 @@
+package regexgen_test
+
+import (
+	"math/big"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/regexgen"
+)
+
+func TestGenerateShouldMatchPattern(t *testing.T) {
+	patterns := []string{
+		`^[A-Za-z0-9]{32}$`,
+		`^(ab|^cd)$|^x$`,
+		`sk_(live|test)_[0-9a-f]{24}`,
+		`[A-Z]{2}-\d{4,6}`,
+		`(?i)key-[a-z]+\.v\d?`,
+		`[^a-z\s]{8}`,
+		`.{4}x*`,
+		`\p{Greek}{3}`,
+	}
+
+	for _, pattern := range patterns {
+		generator, err := regexgen.New(pattern, nil)
+		if !assert.NoError(t, err, pattern) {
+			continue
+		}
+
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for i := 0; i < 50; i++ {
+			value := generator.Generate()
+			assert.Regexp(t, re, value, pattern)
+		}
+	}
+}
+
+func TestGenerateShouldCapRepeats(t *testing.T) {
+	generator, err := regexgen.New(`a+b{2,}`, &regexgen.Config{MaxRepeat: 3, Alphabet: []rune("ab")})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i := 0; i < 50; i++ {
+		value := generator.Generate()
+		assert.Regexp(t, `^a{1,3}b{2,3}$`, value)
+	}
+	assert.Equal(t, big.NewInt(3*2), generator.Keyspace())
+}
+
+func TestKeyspaceShouldSucceed(t *testing.T) {
+	generator, err := regexgen.New(`[a-c]{2}|x`, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, big.NewInt(3*3+1), generator.Keyspace())
+	assert.InDelta(t, 3.3219, generator.Entropy(), 1e-4)
+}
+
+func TestGenerateCaseInsensitiveShouldStayInAlphabet(t *testing.T) {
+	generator, err := regexgen.New(`(?i)ks`, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// The Kelvin sign and the long s are other cases of k and s, but not printable ASCII
+	assert.Equal(t, big.NewInt(2*2), generator.Keyspace())
+	for i := 0; i < 50; i++ {
+		assert.Regexp(t, `^[kK][sS]$`, generator.Generate())
+	}
+}
+
+func TestGenerateShouldBeUniform(t *testing.T) {
+	generator, err := regexgen.New(`[ab]{1,2}|c`, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	counts := map[string]int{"a": 0, "b": 0, "c": 0, "aa": 0, "ab": 0, "ba": 0, "bb": 0}
+	const samplesPerString = 1000
+	for i := 0; i < samplesPerString*len(counts); i++ {
+		value := generator.Generate()
+		if _, ok := counts[value]; !assert.True(t, ok, value) {
+			return
+		}
+		counts[value]++
+	}
+
+	// Pearson's chi-squared test, with the critical value of 6 degrees of freedom at a significance level of 0.0001
+	chiSquared := 0.0
+	for _, count := range counts {
+		difference := float64(count - samplesPerString)
+		chiSquared += difference * difference / samplesPerString
+	}
+	assert.Less(t, chiSquared, 27.856)
+}
+
+func TestNewShouldFail(t *testing.T) {
+	invalidPatterns := []string{`[a-z`, `\bword\b`, `[^\x00-\x{10FFFF}]`, `[\x00-\x08]`, `a$b`, `a^b`, `(?m)a$\nb`, `(a$)+`}
+	for _, pattern := range invalidPatterns {
+		_, err := regexgen.New(pattern, nil)
+		assert.Error(t, err, pattern)
+	}
+
+	_, err := regexgen.New(`a*`, &regexgen.Config{MaxRepeat: -1, Alphabet: []rune("a")})
+	assert.Error(t, err)
+}
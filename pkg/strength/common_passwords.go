@@ -0,0 +1,66 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+// commonPasswords are commonly used passwords, ordered from the most common.
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234", "111111",
+	"1234567", "dragon", "123123", "baseball", "abc123", "football", "monkey", "letmein",
+	"696969", "shadow", "master", "666666", "qwertyuiop", "123321", "mustang", "1234567890",
+	"michael", "654321", "superman", "1qaz2wsx", "7777777", "121212", "000000", "qazwsx",
+	"123qwe", "killer", "trustno1", "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+	"buster", "soccer", "harley", "batman", "andrew", "tigger", "sunshine", "iloveyou",
+	"2000", "charlie", "robert", "thomas", "hockey", "ranger", "daniel", "starwars",
+	"klaster", "112233", "george", "computer", "michelle", "jessica", "pepper", "1111",
+	"zxcvbn", "555555", "11111111", "131313", "freedom", "777777", "pass", "maggie",
+	"159753", "aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer",
+	"love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access", "yankees",
+	"987654321", "dallas", "austin", "thunder", "taylor", "matrix", "william", "corvette",
+	"hello", "martin", "heather", "secret", "merlin", "diamond", "1234qwer", "gfhjkm",
+	"hammer", "silver", "222222", "88888888", "anthony", "justin", "test", "bailey",
+	"q1w2e3r4t5", "patrick", "internet", "scooter", "orange", "11111", "golfer", "cookie",
+	"richard", "samantha", "bigdog", "guitar", "jackson", "whatever", "mickey", "chicken",
+	"sparky", "snoopy", "maverick", "phoenix", "camaro", "peanut", "morgan", "welcome",
+	"falcon", "cowboy", "ferrari", "samsung", "andrea", "smokey", "steelers", "joseph",
+	"mercedes", "dakota", "arsenal", "eagles", "melissa", "boomer", "booboo", "spider",
+	"nascar", "monster", "tigers", "yellow", "xxxxxx", "123123123", "gateway", "marina",
+	"diablo", "bulldog", "qwer1234", "compaq", "purple", "banana", "junior", "hannah",
+	"123654", "porsche", "lakers", "iceman", "money", "cowboys", "987654", "london",
+	"tennis", "999999", "ncc1701", "coffee", "scooby", "0000", "miller", "boston",
+	"q1w2e3r4", "brandon", "yamaha", "chester", "mother", "forever", "johnny", "edward",
+	"333333", "oliver", "redsox", "player", "nikita", "knight", "fender", "barney",
+	"midnight", "please", "brandy", "chicago", "badboy", "slayer", "rangers", "charles",
+	"angel", "flower", "bigdaddy", "rabbit", "wizard", "jasper", "enter", "rachel", "chris",
+	"steven", "winner", "adidas", "victoria", "natasha", "1q2w3e4r", "jasmine", "winter",
+	"prince", "marine", "ghbdtn", "fishing", "cocacola", "casper", "james", "232323",
+	"raiders", "888888", "marlboro", "gandalf", "asdfasdf", "crystal", "87654321",
+	"12344321", "golden", "8675309", "mike", "admin", "root", "changeme", "passw0rd",
+	"password1", "password123", "p@ssw0rd", "welcome1", "qwerty123", "letmein1",
+	"abc123456", "iloveyou1", "admin123", "1q2w3e", "zaq12wsx", "123abc", "test123",
+	"guest", "default", "login", "hello123", "qwe123", "asdf1234", "asdfghjkl",
+	"1qaz2wsx3edc", "monkey123", "dragon123", "football1", "baseball1", "superman1",
+	"princess1", "sunshine1", "shadow1", "master1", "qwerty1", "abcd1234", "a1b2c3",
+	"aa123456", "password12", "passpass", "secret123", "administrator", "letmein123",
+}
@@ -0,0 +1,260 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	minDateYear = 1000
+	maxDateYear = 2050
+)
+
+const dateSeparators = " /\\_.-"
+
+// dateSplits are the positions to split digit-only dates of each length into day, month and year.
+var dateSplits = map[int][][2]int{
+	4: {{1, 2}, {2, 3}},                 // 1191 or 9111
+	5: {{1, 3}, {2, 3}},                 // 11191 or 12191
+	6: {{1, 2}, {2, 4}, {4, 5}},         // 111991, 119911 or 991111
+	7: {{1, 3}, {2, 3}, {4, 5}, {4, 6}}, // 1111991, 1211991, 1991111 or 1991211
+	8: {{2, 4}, {4, 6}},                 // 11111991 or 19911111
+}
+
+type dmy struct {
+	day, month, year int
+}
+
+func referenceYear() int {
+	return time.Now().Year()
+}
+
+func dateMatch(password []rune) []*Match {
+	var matches []*Match
+
+	// Dates without separators, such as 13051990
+	for i := 0; i+4 <= len(password); i++ {
+		for j := i + 3; j <= i+7 && j < len(password); j++ {
+			token := password[i : j+1]
+			if !allRunes(token, isDigit) {
+				break
+			}
+
+			var best *dmy
+			for _, split := range dateSplits[len(token)] {
+				date := mapIntsToDMY([3]int{
+					atoi(token[:split[0]]),
+					atoi(token[split[0]:split[1]]),
+					atoi(token[split[1]:]),
+				})
+				if date != nil && (best == nil || yearDistance(date.year) < yearDistance(best.year)) {
+					best = date
+				}
+			}
+			if best != nil {
+				matches = append(matches, newDateMatch(password, i, j, "", best))
+			}
+		}
+	}
+
+	// Dates with separators, such as 13/05/1990 or 1990-05-13
+	for i := 0; i+6 <= len(password); i++ {
+		for j := i + 5; j <= i+9 && j < len(password); j++ {
+			if date, separator := parseSeparatedDate(password[i : j+1]); date != nil {
+				matches = append(matches, newDateMatch(password, i, j, separator, date))
+			}
+		}
+	}
+
+	matches = removeSubmatches(matches)
+	return append(matches, yearMatch(password)...)
+}
+
+// yearMatch finds recent years, such as 1990 or 2019.
+func yearMatch(password []rune) []*Match {
+	var matches []*Match
+	for i := 0; i+4 <= len(password); {
+		token := password[i : i+4]
+		if allRunes(token, isDigit) && (token[0] == '1' && token[1] == '9' || token[0] == '2' && token[1] == '0') {
+			matches = append(matches, &Match{
+				Pattern: DatePattern,
+				I:       i,
+				J:       i + 3,
+				Token:   string(token),
+				Year:    atoi(token),
+			})
+			i += 4
+		} else {
+			i++
+		}
+	}
+	return matches
+}
+
+func parseSeparatedDate(token []rune) (*dmy, string) {
+	first := 0
+	for first < len(token) && isDigit(token[first]) {
+		first++
+	}
+	if first == 0 || first > 4 || first >= len(token) || !strings.ContainsRune(dateSeparators, token[first]) {
+		return nil, ""
+	}
+
+	separator := token[first]
+	second := first + 1
+	for second < len(token) && isDigit(token[second]) {
+		second++
+	}
+	if second-first-1 < 1 || second-first-1 > 2 || second >= len(token) || token[second] != separator {
+		return nil, ""
+	}
+
+	rest := token[second+1:]
+	if len(rest) < 1 || len(rest) > 4 || !allRunes(rest, isDigit) {
+		return nil, ""
+	}
+
+	date := mapIntsToDMY([3]int{atoi(token[:first]), atoi(token[first+1 : second]), atoi(rest)})
+	if date == nil {
+		return nil, ""
+	}
+	return date, string(separator)
+}
+
+func mapIntsToDMY(ints [3]int) *dmy {
+	// The middle value is always a day or a month
+	if ints[1] > 31 || ints[1] <= 0 {
+		return nil
+	}
+
+	over12, over31, under1 := 0, 0, 0
+	for _, value := range ints {
+		if value > 99 && value < minDateYear || value > maxDateYear {
+			return nil
+		}
+		if value > 31 {
+			over31++
+		}
+		if value > 12 {
+			over12++
+		}
+		if value <= 0 {
+			under1++
+		}
+	}
+	if over31 >= 2 || over12 == 3 || under1 >= 2 {
+		return nil
+	}
+
+	yearSplits := []struct {
+		year int
+		rest [2]int
+	}{
+		{ints[2], [2]int{ints[0], ints[1]}},
+		{ints[0], [2]int{ints[1], ints[2]}},
+	}
+
+	// A four-digit year has to be followed or preceded by a valid day and month
+	for _, split := range yearSplits {
+		if split.year >= minDateYear && split.year <= maxDateYear {
+			day, month, ok := mapIntsToDM(split.rest)
+			if !ok {
+				return nil
+			}
+			return &dmy{day: day, month: month, year: split.year}
+		}
+	}
+
+	for _, split := range yearSplits {
+		if day, month, ok := mapIntsToDM(split.rest); ok {
+			return &dmy{day: day, month: month, year: twoToFourDigitYear(split.year)}
+		}
+	}
+	return nil
+}
+
+func mapIntsToDM(ints [2]int) (day, month int, ok bool) {
+	for _, candidate := range [][2]int{ints, {ints[1], ints[0]}} {
+		if candidate[0] >= 1 && candidate[0] <= 31 && candidate[1] >= 1 && candidate[1] <= 12 {
+			return candidate[0], candidate[1], true
+		}
+	}
+	return 0, 0, false
+}
+
+func twoToFourDigitYear(year int) int {
+	switch {
+	case year > 99:
+		return year
+	case year > 50:
+		return year + 1900
+	default:
+		return year + 2000
+	}
+}
+
+func newDateMatch(password []rune, i, j int, separator string, date *dmy) *Match {
+	return &Match{
+		Pattern:   DatePattern,
+		I:         i,
+		J:         j,
+		Token:     string(password[i : j+1]),
+		Separator: separator,
+		Year:      date.year,
+		Month:     date.month,
+		Day:       date.day,
+	}
+}
+
+// removeSubmatches removes matches that are strictly contained in other matches,
+// such as 1/1/91 in 1/1/1991.
+func removeSubmatches(matches []*Match) []*Match {
+	var result []*Match
+	for _, match := range matches {
+		contained := false
+		for _, other := range matches {
+			if match != other && other.I <= match.I && other.J >= match.J {
+				contained = true
+				break
+			}
+		}
+		if !contained {
+			result = append(result, match)
+		}
+	}
+	return result
+}
+
+func yearDistance(year int) int {
+	return abs(year - referenceYear())
+}
+
+func atoi(runes []rune) int {
+	value, _ := strconv.Atoi(string(runes))
+	return value
+}
@@ -0,0 +1,246 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/whinarn/strongpass/internal/wordlist"
+)
+
+const (
+	passwordsDictionaryName  = "passwords"
+	englishDictionaryName    = "english"
+	userInputsDictionaryName = "user_inputs"
+)
+
+// rankedDictionary maps lower-case words to their rank, where a lower rank is more common.
+type rankedDictionary map[string]int
+
+type namedDictionary struct {
+	name  string
+	ranks rankedDictionary
+}
+
+var (
+	dictionariesOnce sync.Once
+	dictionaries     []namedDictionary
+)
+
+var l33tTable = map[rune][]rune{
+	'a': []rune("4@"),
+	'b': []rune("8"),
+	'c': []rune("({[<"),
+	'e': []rune("3"),
+	'g': []rune("69"),
+	'i': []rune("1!|"),
+	'l': []rune("1|7"),
+	'o': []rune("0"),
+	's': []rune("$5"),
+	't': []rune("+7"),
+	'x': []rune("%"),
+	'z': []rune("2"),
+}
+
+func rankedDictionaries() []namedDictionary {
+	dictionariesOnce.Do(func() {
+		english := make(rankedDictionary)
+		for _, words := range [][]string{wordlist.EFFLarge, wordlist.EFFShort} {
+			// The wordlists are not ordered by frequency, so every word is ranked by the size of its list
+			for _, word := range words {
+				if rank, ok := english[word]; !ok || len(words) < rank {
+					english[word] = len(words)
+				}
+			}
+		}
+
+		dictionaries = []namedDictionary{
+			{name: passwordsDictionaryName, ranks: newRankedDictionary(commonPasswords)},
+			{name: englishDictionaryName, ranks: english},
+		}
+	})
+
+	result := make([]namedDictionary, len(dictionaries))
+	copy(result, dictionaries)
+	return result
+}
+
+// newRankedDictionary ranks words by their order in the list.
+func newRankedDictionary(words []string) rankedDictionary {
+	dictionary := make(rankedDictionary, len(words))
+	for i, word := range words {
+		word = strings.ToLower(word)
+		if _, ok := dictionary[word]; !ok && len(word) > 0 {
+			dictionary[word] = i + 1
+		}
+	}
+	return dictionary
+}
+
+func dictionaryMatch(password []rune, dictionaries []namedDictionary) []*Match {
+	lowerPassword := toLowerRunes(password)
+
+	var matches []*Match
+	for i := range password {
+		for j := i; j < len(password); j++ {
+			word := string(lowerPassword[i : j+1])
+			for _, dictionary := range dictionaries {
+				if rank, ok := dictionary.ranks[word]; ok {
+					matches = append(matches, &Match{
+						Pattern:        DictionaryPattern,
+						I:              i,
+						J:              j,
+						Token:          string(password[i : j+1]),
+						MatchedWord:    word,
+						Rank:           rank,
+						DictionaryName: dictionary.name,
+					})
+				}
+			}
+		}
+	}
+	return matches
+}
+
+func reverseDictionaryMatch(password []rune, dictionaries []namedDictionary) []*Match {
+	reversed := reverseRunes(password)
+	matches := dictionaryMatch(reversed, dictionaries)
+	for _, match := range matches {
+		match.Token = string(reverseRunes([]rune(match.Token)))
+		match.Reversed = true
+		match.I, match.J = len(password)-1-match.J, len(password)-1-match.I
+	}
+	return matches
+}
+
+func l33tMatch(password []rune, dictionaries []namedDictionary) []*Match {
+	var matches []*Match
+	for _, substitutions := range enumerateL33tSubstitutions(password) {
+		translated := make([]rune, len(password))
+		for i, r := range password {
+			if letter, ok := substitutions[r]; ok {
+				translated[i] = letter
+			} else {
+				translated[i] = r
+			}
+		}
+
+		for _, match := range dictionaryMatch(translated, dictionaries) {
+			token := password[match.I : match.J+1]
+			if string(toLowerRunes(token)) == match.MatchedWord {
+				// Only the original dictionary matcher should match tokens without substitutions
+				continue
+			}
+
+			matchSubstitutions := make(map[rune]rune)
+			for _, r := range token {
+				if letter, ok := substitutions[r]; ok {
+					matchSubstitutions[r] = letter
+				}
+			}
+			if len(token) <= 1 || len(matchSubstitutions) == 0 {
+				// Single l33t characters match too many words to be meaningful
+				continue
+			}
+
+			match.Token = string(token)
+			match.L33t = true
+			match.Substitutions = matchSubstitutions
+			matches = append(matches, match)
+		}
+	}
+	return matches
+}
+
+// enumerateL33tSubstitutions returns every way to map the l33t characters of the
+// password back to letters, where each l33t character maps to exactly one letter.
+func enumerateL33tSubstitutions(password []rune) []map[rune]rune {
+	candidates := make(map[rune][]rune)
+	for letter, subs := range l33tTable {
+		for _, sub := range subs {
+			for _, r := range password {
+				if r == sub {
+					candidates[sub] = appendUniqueRune(candidates[sub], letter)
+					break
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	subs := make([]rune, 0, len(candidates))
+	for sub, letters := range candidates {
+		sort.Slice(letters, func(a, b int) bool { return letters[a] < letters[b] })
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(a, b int) bool { return subs[a] < subs[b] })
+
+	result := []map[rune]rune{{}}
+	for _, sub := range subs {
+		var next []map[rune]rune
+		for _, partial := range result {
+			for _, letter := range candidates[sub] {
+				substitutions := make(map[rune]rune, len(partial)+1)
+				for k, v := range partial {
+					substitutions[k] = v
+				}
+				substitutions[sub] = letter
+				next = append(next, substitutions)
+			}
+		}
+		result = next
+	}
+	return result
+}
+
+func appendUniqueRune(runes []rune, r rune) []rune {
+	for _, existing := range runes {
+		if existing == r {
+			return runes
+		}
+	}
+	return append(runes, r)
+}
+
+func toLowerRunes(runes []rune) []rune {
+	result := make([]rune, len(runes))
+	for i, r := range runes {
+		result[i] = unicode.ToLower(r)
+	}
+	return result
+}
+
+func reverseRunes(runes []rune) []rune {
+	result := make([]rune, len(runes))
+	for i, r := range runes {
+		result[len(runes)-1-i] = r
+	}
+	return result
+}
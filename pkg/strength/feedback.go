@@ -0,0 +1,149 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+import (
+	"unicode"
+)
+
+var defaultFeedback = Feedback{
+	Suggestions: []string{
+		"Use a few words, avoid common phrases",
+		"No need for symbols, digits, or uppercase letters",
+	},
+}
+
+func getFeedback(score Score, sequence []*Match) Feedback {
+	if len(sequence) == 0 {
+		return defaultFeedback
+	}
+	if score > SomewhatGuessable {
+		return Feedback{}
+	}
+
+	longestMatch := sequence[0]
+	for _, match := range sequence[1:] {
+		if len([]rune(match.Token)) > len([]rune(longestMatch.Token)) {
+			longestMatch = match
+		}
+	}
+
+	feedback := getMatchFeedback(longestMatch, len(sequence) == 1)
+	extraSuggestion := "Add another word or two. Uncommon words are better."
+	feedback.Suggestions = append([]string{extraSuggestion}, feedback.Suggestions...)
+	return feedback
+}
+
+func getMatchFeedback(match *Match, isSoleMatch bool) Feedback {
+	switch match.Pattern {
+	case DictionaryPattern:
+		return getDictionaryMatchFeedback(match, isSoleMatch)
+	case SpatialPattern:
+		warning := "Short keyboard patterns are easy to guess"
+		if match.Turns == 1 {
+			warning = "Straight rows of keys are easy to guess"
+		}
+		return Feedback{
+			Warning:     warning,
+			Suggestions: []string{"Use a longer keyboard pattern with more turns"},
+		}
+	case RepeatPattern:
+		warning := `Repeats like "abcabcabc" are only slightly harder to guess than "abc"`
+		if len([]rune(match.BaseToken)) == 1 {
+			warning = `Repeats like "aaa" are easy to guess`
+		}
+		return Feedback{
+			Warning:     warning,
+			Suggestions: []string{"Avoid repeated words and characters"},
+		}
+	case SequencePattern:
+		return Feedback{
+			Warning:     "Sequences like abc or 6543 are easy to guess",
+			Suggestions: []string{"Avoid sequences"},
+		}
+	case DatePattern:
+		if match.Month == 0 {
+			return Feedback{
+				Warning:     "Recent years are easy to guess",
+				Suggestions: []string{"Avoid recent years", "Avoid years that are associated with you"},
+			}
+		}
+		return Feedback{
+			Warning:     "Dates are often easy to guess",
+			Suggestions: []string{"Avoid dates and years that are associated with you"},
+		}
+	default:
+		return Feedback{}
+	}
+}
+
+func getDictionaryMatchFeedback(match *Match, isSoleMatch bool) Feedback {
+	var feedback Feedback
+	switch match.DictionaryName {
+	case passwordsDictionaryName:
+		if isSoleMatch && !match.L33t && !match.Reversed {
+			switch {
+			case match.Rank <= 10:
+				feedback.Warning = "This is a top-10 common password"
+			case match.Rank <= 100:
+				feedback.Warning = "This is a top-100 common password"
+			default:
+				feedback.Warning = "This is a very common password"
+			}
+		} else if match.Guesses <= 1e4 {
+			feedback.Warning = "This is similar to a commonly used password"
+		}
+	case englishDictionaryName:
+		if isSoleMatch {
+			feedback.Warning = "A word by itself is easy to guess"
+		}
+	case userInputsDictionaryName:
+		feedback.Warning = "Personal information is easy to guess"
+	}
+
+	runes := []rune(match.Token)
+	if unicode.IsUpper(runes[0]) && countUpper(runes) == 1 {
+		feedback.Suggestions = append(feedback.Suggestions, "Capitalization doesn't help very much")
+	} else if countUpper(runes) == len(runes) && string(toLowerRunes(runes)) != match.Token {
+		feedback.Suggestions = append(feedback.Suggestions, "All-uppercase is almost as easy to guess as all-lowercase")
+	}
+	if match.Reversed && len(runes) >= 4 {
+		feedback.Suggestions = append(feedback.Suggestions, "Reversed words aren't much harder to guess")
+	}
+	if match.L33t {
+		feedback.Suggestions = append(feedback.Suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+	}
+	return feedback
+}
+
+func countUpper(runes []rune) int {
+	count := 0
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,122 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+import (
+	"sort"
+)
+
+// Pattern is the kind of pattern a match was found with.
+type Pattern string
+
+const (
+	// DictionaryPattern matches common passwords, words and user inputs, including reversed and l33t variants.
+	DictionaryPattern Pattern = "dictionary"
+	// SpatialPattern matches keyboard walks, such as "qwerty" or "zxcvfr".
+	SpatialPattern Pattern = "spatial"
+	// RepeatPattern matches repeated characters or strings, such as "aaa" or "abcabc".
+	RepeatPattern Pattern = "repeat"
+	// SequencePattern matches character sequences, such as "abc" or "9753".
+	SequencePattern Pattern = "sequence"
+	// DatePattern matches dates and years, such as "1990", "13/05/1990" or "130590".
+	DatePattern Pattern = "date"
+	// BruteforcePattern covers the parts of a password that match no other pattern.
+	BruteforcePattern Pattern = "bruteforce"
+)
+
+// Match is a part of a password that matches a pattern.
+// Only the fields relevant to the pattern are set.
+type Match struct {
+	Pattern Pattern
+	// I and J are the inclusive rune indexes of the match in the password.
+	I, J    int
+	Token   string
+	Guesses float64
+
+	// Dictionary patterns
+	MatchedWord    string
+	Rank           int
+	DictionaryName string
+	Reversed       bool
+	L33t           bool
+	Substitutions  map[rune]rune
+
+	// Spatial patterns
+	Graph        string
+	Turns        int
+	ShiftedCount int
+
+	// Repeat patterns
+	BaseToken   string
+	BaseGuesses float64
+	RepeatCount int
+
+	// Sequence patterns
+	SequenceName  string
+	SequenceSpace int
+	Ascending     bool
+
+	// Date patterns, where Month and Day are zero if only a year was matched
+	Separator string
+	Year      int
+	Month     int
+	Day       int
+}
+
+type matcher func(password []rune) []*Match
+
+// omnimatch finds all matches of all patterns in a password.
+func omnimatch(password []rune, userInputs rankedDictionary) []*Match {
+	dictionaries := rankedDictionaries()
+	if len(userInputs) > 0 {
+		dictionaries = append(dictionaries, namedDictionary{name: userInputsDictionaryName, ranks: userInputs})
+	}
+
+	matchers := []matcher{
+		func(password []rune) []*Match { return dictionaryMatch(password, dictionaries) },
+		func(password []rune) []*Match { return reverseDictionaryMatch(password, dictionaries) },
+		func(password []rune) []*Match { return l33tMatch(password, dictionaries) },
+		spatialMatch,
+		repeatMatch,
+		sequenceMatch,
+		dateMatch,
+	}
+
+	var matches []*Match
+	for _, match := range matchers {
+		matches = append(matches, match(password)...)
+	}
+	sortMatches(matches)
+	return matches
+}
+
+func sortMatches(matches []*Match) {
+	sort.SliceStable(matches, func(a, b int) bool {
+		if matches[a].I != matches[b].I {
+			return matches[a].I < matches[b].I
+		}
+		return matches[a].J < matches[b].J
+	})
+}
@@ -0,0 +1,102 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+func repeatMatch(password []rune) []*Match {
+	var matches []*Match
+	i := 0
+	for i < len(password) {
+		baseLength, repeatCount := longestRepeat(password, i)
+		if repeatCount < 2 {
+			i++
+			continue
+		}
+
+		j := i + baseLength*repeatCount - 1
+		token := password[i : j+1]
+		base := token[:smallestPeriod(token)]
+		baseGuesses, _ := mostGuessableMatchSequence(base, omnimatch(base, nil), false)
+
+		matches = append(matches, &Match{
+			Pattern:     RepeatPattern,
+			I:           i,
+			J:           j,
+			Token:       string(token),
+			BaseToken:   string(base),
+			BaseGuesses: baseGuesses,
+			RepeatCount: len(token) / len(base),
+		})
+		i = j + 1
+	}
+	return matches
+}
+
+// longestRepeat finds the base length that covers the most characters when repeated
+// consecutively from the start index, preferring shorter bases on ties.
+func longestRepeat(password []rune, start int) (baseLength int, repeatCount int) {
+	bestLength := 0
+	for length := 1; start+2*length <= len(password); length++ {
+		count := 1
+		for start+(count+1)*length <= len(password) &&
+			equalRunes(password[start:start+length], password[start+count*length:start+(count+1)*length]) {
+			count++
+		}
+		if count >= 2 && length*count > bestLength {
+			bestLength = length * count
+			baseLength, repeatCount = length, count
+		}
+	}
+	return baseLength, repeatCount
+}
+
+// smallestPeriod returns the length of the shortest string that repeated forms the token.
+func smallestPeriod(token []rune) int {
+	for length := 1; length < len(token); length++ {
+		if len(token)%length != 0 {
+			continue
+		}
+
+		periodic := true
+		for i := length; i < len(token) && periodic; i++ {
+			periodic = token[i] == token[i-length]
+		}
+		if periodic {
+			return length
+		}
+	}
+	return len(token)
+}
+
+func equalRunes(a, b []rune) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,364 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+import (
+	"math"
+	"unicode"
+)
+
+const (
+	bruteforceCardinality        = 10
+	minGuessesBeforeGrowingSeq   = 10000
+	minSubmatchGuessesSingleChar = 10
+	minSubmatchGuessesMultiChar  = 50
+	minYearSpace                 = 20
+	daysPerYear                  = 365
+	dateSeparatorMultiplier      = 4
+	reversedMultiplier           = 2
+	allShiftedMultiplier         = 2
+)
+
+// mostGuessableMatchSequence finds the sequence of non-overlapping matches that covers the
+// password with the fewest total guesses, filling gaps with bruteforce matches.
+//
+// For a sequence of l matches the total is l! times the product of the match guesses, since
+// the attacker does not know the order of the patterns, plus a penalty that grows with l to
+// favour fewer and longer matches.
+func mostGuessableMatchSequence(password []rune, matches []*Match, excludeAdditive bool) (float64, []*Match) {
+	n := len(password)
+	if n == 0 {
+		return 1, nil
+	}
+
+	matchesByJ := make([][]*Match, n)
+	for _, match := range matches {
+		matchesByJ[match.J] = append(matchesByJ[match.J], match)
+	}
+	for _, byJ := range matchesByJ {
+		sortMatches(byJ)
+	}
+
+	// For every end index k and sequence length l, the best match ending at k,
+	// the product of guesses of the sequence, and the total guesses of the sequence
+	optimalMatch := make([]map[int]*Match, n)
+	optimalProduct := make([]map[int]float64, n)
+	optimalGuesses := make([]map[int]float64, n)
+	for k := 0; k < n; k++ {
+		optimalMatch[k] = make(map[int]*Match)
+		optimalProduct[k] = make(map[int]float64)
+		optimalGuesses[k] = make(map[int]float64)
+	}
+
+	update := func(match *Match, l int) {
+		k := match.J
+		product := estimateGuesses(match, n)
+		if l > 1 {
+			product *= optimalProduct[match.I-1][l-1]
+		}
+		guesses := factorial(l) * product
+		if !excludeAdditive {
+			guesses += math.Pow(minGuessesBeforeGrowingSeq, float64(l-1))
+		}
+
+		// A sequence is only kept if no shorter or equally long sequence is as good
+		for competingL, competingGuesses := range optimalGuesses[k] {
+			if competingL <= l && competingGuesses <= guesses {
+				return
+			}
+		}
+		optimalMatch[k][l] = match
+		optimalProduct[k][l] = product
+		optimalGuesses[k][l] = guesses
+	}
+
+	bruteforceUpdate := func(k int) {
+		update(newBruteforceMatch(password, 0, k), 1)
+		for i := 1; i <= k; i++ {
+			match := newBruteforceMatch(password, i, k)
+			for l, lastMatch := range optimalMatch[i-1] {
+				// Consecutive bruteforce matches are never better than a single one
+				if lastMatch.Pattern == BruteforcePattern {
+					continue
+				}
+				update(match, l+1)
+			}
+		}
+	}
+
+	for k := 0; k < n; k++ {
+		for _, match := range matchesByJ[k] {
+			if match.I > 0 {
+				for l := range optimalMatch[match.I-1] {
+					update(match, l+1)
+				}
+			} else {
+				update(match, 1)
+			}
+		}
+		bruteforceUpdate(k)
+	}
+
+	// Unwind the optimal sequence from the end of the password
+	bestL := 0
+	bestGuesses := math.Inf(1)
+	for l, guesses := range optimalGuesses[n-1] {
+		if guesses < bestGuesses || guesses == bestGuesses && l < bestL {
+			bestL = l
+			bestGuesses = guesses
+		}
+	}
+
+	sequence := make([]*Match, bestL)
+	for k, l := n-1, bestL; k >= 0; l-- {
+		match := optimalMatch[k][l]
+		sequence[l-1] = match
+		k = match.I - 1
+	}
+	return bestGuesses, sequence
+}
+
+func newBruteforceMatch(password []rune, i, j int) *Match {
+	return &Match{
+		Pattern: BruteforcePattern,
+		I:       i,
+		J:       j,
+		Token:   string(password[i : j+1]),
+	}
+}
+
+// estimateGuesses estimates the guesses of a match in a password of the specified length.
+func estimateGuesses(match *Match, passwordLength int) float64 {
+	if match.Guesses > 0 {
+		return match.Guesses
+	}
+
+	tokenLength := len([]rune(match.Token))
+	minGuesses := 1.0
+	if tokenLength < passwordLength {
+		// Submatches get a minimum so that a few characters are never considered free
+		if tokenLength == 1 {
+			minGuesses = minSubmatchGuessesSingleChar
+		} else {
+			minGuesses = minSubmatchGuessesMultiChar
+		}
+	}
+
+	var guesses float64
+	switch match.Pattern {
+	case BruteforcePattern:
+		guesses = bruteforceGuesses(match, tokenLength)
+	case DictionaryPattern:
+		guesses = dictionaryGuesses(match)
+	case SpatialPattern:
+		guesses = spatialGuesses(match, tokenLength)
+	case RepeatPattern:
+		guesses = match.BaseGuesses * float64(match.RepeatCount)
+	case SequencePattern:
+		guesses = sequenceGuesses(match, tokenLength)
+	case DatePattern:
+		guesses = dateGuesses(match)
+	}
+
+	match.Guesses = math.Max(guesses, minGuesses)
+	return match.Guesses
+}
+
+func bruteforceGuesses(match *Match, tokenLength int) float64 {
+	guesses := math.Pow(bruteforceCardinality, float64(tokenLength))
+	if math.IsInf(guesses, 1) {
+		guesses = math.MaxFloat64
+	}
+
+	// Bruteforce matches get one more than the submatch minimum, so they never win a tie
+	minGuesses := float64(minSubmatchGuessesMultiChar + 1)
+	if tokenLength == 1 {
+		minGuesses = minSubmatchGuessesSingleChar + 1
+	}
+	return math.Max(guesses, minGuesses)
+}
+
+func dictionaryGuesses(match *Match) float64 {
+	guesses := float64(match.Rank) * uppercaseVariations(match.Token) * l33tVariations(match)
+	if match.Reversed {
+		guesses *= reversedMultiplier
+	}
+	return guesses
+}
+
+// uppercaseVariations is the number of ways the token could be capitalized, where the
+// common ways of capitalizing the first, the last or all letters are cheap to guess.
+func uppercaseVariations(token string) float64 {
+	runes := []rune(token)
+	upper, lower := 0, 0
+	for _, r := range runes {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+
+	firstUpper := unicode.IsUpper(runes[0]) && upper == 1
+	lastUpper := unicode.IsUpper(runes[len(runes)-1]) && upper == 1
+	if firstUpper || lastUpper || lower == 0 {
+		return 2
+	}
+
+	variations := 0.0
+	for i := 1; i <= upper && i <= lower; i++ {
+		variations += binomial(upper+lower, i)
+	}
+	return variations
+}
+
+// l33tVariations is the number of ways the substituted characters could have been chosen.
+func l33tVariations(match *Match) float64 {
+	if !match.L33t {
+		return 1
+	}
+
+	variations := 1.0
+	for sub, letter := range match.Substitutions {
+		subbed, unsubbed := 0, 0
+		for _, r := range toLowerRunes([]rune(match.Token)) {
+			if r == sub {
+				subbed++
+			} else if r == letter {
+				unsubbed++
+			}
+		}
+
+		if subbed == 0 || unsubbed == 0 {
+			// Either everything or nothing is substituted, which only doubles the guesses
+			variations *= 2
+		} else {
+			possibilities := 0.0
+			for i := 1; i <= subbed && i <= unsubbed; i++ {
+				possibilities += binomial(subbed+unsubbed, i)
+			}
+			variations *= possibilities
+		}
+	}
+	return variations
+}
+
+func spatialGuesses(match *Match, tokenLength int) float64 {
+	var graph namedGraph
+	for _, candidate := range spatialGraphs {
+		if candidate.name == match.Graph {
+			graph = candidate
+		}
+	}
+
+	// Sum the possible walks of every length up to the token length with up to the found number of turns
+	guesses := 0.0
+	startingPositions := float64(graph.startingPositions)
+	for i := 2; i <= tokenLength; i++ {
+		possibleTurns := match.Turns
+		if i-1 < possibleTurns {
+			possibleTurns = i - 1
+		}
+		for j := 1; j <= possibleTurns; j++ {
+			guesses += binomial(i-1, j-1) * startingPositions * math.Pow(graph.averageDegree, float64(j))
+		}
+	}
+
+	if match.ShiftedCount > 0 {
+		shifted := match.ShiftedCount
+		unshifted := tokenLength - shifted
+		if unshifted == 0 {
+			guesses *= allShiftedMultiplier
+		} else {
+			variations := 0.0
+			for i := 1; i <= shifted && i <= unshifted; i++ {
+				variations += binomial(shifted+unshifted, i)
+			}
+			guesses *= variations
+		}
+	}
+	return guesses
+}
+
+func sequenceGuesses(match *Match, tokenLength int) float64 {
+	first := []rune(match.Token)[0]
+
+	var baseGuesses float64
+	switch {
+	case first == 'a' || first == 'A' || first == 'z' || first == 'Z' || first == '0' || first == '1' || first == '9':
+		// Obvious starting points
+		baseGuesses = 4
+	case isDigit(first):
+		baseGuesses = 10
+	default:
+		baseGuesses = 26
+	}
+	if !match.Ascending {
+		baseGuesses *= 2
+	}
+	return baseGuesses * float64(tokenLength)
+}
+
+func dateGuesses(match *Match) float64 {
+	yearSpace := math.Max(float64(yearDistance(match.Year)), minYearSpace)
+	if match.Month == 0 {
+		// Only a year
+		return yearSpace
+	}
+
+	guesses := yearSpace * daysPerYear
+	if match.Separator != "" {
+		guesses *= dateSeparatorMultiplier
+	}
+	return guesses
+}
+
+func binomial(n, k int) float64 {
+	if k > n {
+		return 0
+	}
+	if k == 0 {
+		return 1
+	}
+
+	result := 1.0
+	for d := 1; d <= k; d++ {
+		result *= float64(n)
+		result /= float64(d)
+		n--
+	}
+	return result
+}
+
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
@@ -0,0 +1,103 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+// maxSequenceDelta is the largest step between characters that is considered a sequence.
+const maxSequenceDelta = 5
+
+func sequenceMatch(password []rune) []*Match {
+	if len(password) <= 1 {
+		return nil
+	}
+
+	var matches []*Match
+	update := func(i, j, delta int) {
+		// Sequences of two characters are only considered with a step of one, like "ab"
+		if j-i > 1 || abs(delta) == 1 {
+			if delta != 0 && abs(delta) <= maxSequenceDelta {
+				token := password[i : j+1]
+				name, space := classifySequence(token)
+				matches = append(matches, &Match{
+					Pattern:       SequencePattern,
+					I:             i,
+					J:             j,
+					Token:         string(token),
+					SequenceName:  name,
+					SequenceSpace: space,
+					Ascending:     delta > 0,
+				})
+			}
+		}
+	}
+
+	i := 0
+	lastDelta := int(password[1]) - int(password[0])
+	for k := 2; k < len(password); k++ {
+		delta := int(password[k]) - int(password[k-1])
+		if delta == lastDelta {
+			continue
+		}
+
+		j := k - 1
+		update(i, j, lastDelta)
+		i = j
+		lastDelta = delta
+	}
+	update(i, len(password)-1, lastDelta)
+	return matches
+}
+
+func classifySequence(token []rune) (name string, space int) {
+	switch {
+	case allRunes(token, func(r rune) bool { return r >= 'a' && r <= 'z' }):
+		return "lower", 26
+	case allRunes(token, func(r rune) bool { return r >= 'A' && r <= 'Z' }):
+		return "upper", 26
+	case allRunes(token, isDigit):
+		return "digits", 10
+	default:
+		return "unicode", 26
+	}
+}
+
+func allRunes(runes []rune, predicate func(r rune) bool) bool {
+	for _, r := range runes {
+		if !predicate(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,209 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+import (
+	"strings"
+)
+
+const (
+	qwertyGraphName = "qwerty"
+	keypadGraphName = "keypad"
+)
+
+const qwertyLayout = "" +
+	"`~ 1! 2@ 3# 4$ 5% 6^ 7& 8* 9( 0) -_ =+\n" +
+	"    qQ wW eE rR tT yY uU iI oO pP [{ ]} \\|\n" +
+	"     aA sS dD fF gG hH jJ kK lL ;: '\"\n" +
+	"      zZ xX cC vV bB nN mM ,< .> /?"
+
+const keypadLayout = "" +
+	"  / * -\n" +
+	"7 8 9 +\n" +
+	"4 5 6\n" +
+	"1 2 3\n" +
+	"  0 ."
+
+// adjacencyGraph maps each key character to its neighbouring keys, in clockwise order
+// starting from the left. A neighbour is empty if there is no key in that direction.
+type adjacencyGraph map[rune][]string
+
+type namedGraph struct {
+	name  string
+	graph adjacencyGraph
+	// startingPositions is the number of keys and averageDegree the average number of neighbours per key
+	startingPositions int
+	averageDegree     float64
+}
+
+var spatialGraphs = []namedGraph{
+	newNamedGraph(qwertyGraphName, buildAdjacencyGraph(qwertyLayout, true)),
+	newNamedGraph(keypadGraphName, buildAdjacencyGraph(keypadLayout, false)),
+}
+
+type keyPosition struct {
+	x, y int
+}
+
+// buildAdjacencyGraph builds an adjacency graph from a keyboard layout. Keys are tokens of
+// equal length, with the unshifted character first. On slanted layouts each row is shifted
+// one character further to the right than the row above, like on a real keyboard.
+func buildAdjacencyGraph(layout string, slanted bool) adjacencyGraph {
+	lines := strings.Split(layout, "\n")
+	tokenSize := len(strings.Fields(lines[0])[0])
+	xUnit := tokenSize + 1
+
+	positions := make(map[keyPosition]string)
+	for y, line := range lines {
+		slant := 0
+		if slanted {
+			slant = y
+		}
+		for x := 0; x < len(line); x++ {
+			if line[x] == ' ' {
+				continue
+			}
+			positions[keyPosition{x: (x - slant) / xUnit, y: y}] = line[x : x+tokenSize]
+			x += tokenSize - 1
+		}
+	}
+
+	graph := make(adjacencyGraph)
+	for position, key := range positions {
+		var neighbours []string
+		for _, neighbour := range adjacentPositions(position, slanted) {
+			neighbours = append(neighbours, positions[neighbour])
+		}
+		for _, r := range key {
+			graph[r] = neighbours
+		}
+	}
+	return graph
+}
+
+func adjacentPositions(position keyPosition, slanted bool) []keyPosition {
+	x, y := position.x, position.y
+	if slanted {
+		return []keyPosition{{x - 1, y}, {x, y - 1}, {x + 1, y - 1}, {x + 1, y}, {x, y + 1}, {x - 1, y + 1}}
+	}
+	return []keyPosition{{x - 1, y}, {x - 1, y - 1}, {x, y - 1}, {x + 1, y - 1},
+		{x + 1, y}, {x + 1, y + 1}, {x, y + 1}, {x - 1, y + 1}}
+}
+
+func newNamedGraph(name string, graph adjacencyGraph) namedGraph {
+	neighbourCount := 0
+	for _, neighbours := range graph {
+		for _, neighbour := range neighbours {
+			if neighbour != "" {
+				neighbourCount++
+			}
+		}
+	}
+
+	return namedGraph{
+		name:              name,
+		graph:             graph,
+		startingPositions: len(graph),
+		averageDegree:     float64(neighbourCount) / float64(len(graph)),
+	}
+}
+
+func spatialMatch(password []rune) []*Match {
+	var matches []*Match
+	for _, graph := range spatialGraphs {
+		matches = append(matches, spatialMatchGraph(password, graph)...)
+	}
+	return matches
+}
+
+func spatialMatchGraph(password []rune, graph namedGraph) []*Match {
+	var matches []*Match
+	i := 0
+	for i < len(password)-1 {
+		j := i + 1
+		lastDirection := -1
+		turns := 0
+		shiftedCount := 0
+		if graph.name == qwertyGraphName && isShiftedKey(graph.graph, password[i]) {
+			shiftedCount = 1
+		}
+
+		for {
+			found := false
+			if j < len(password) {
+				previous := password[j-1]
+				for direction, neighbour := range graph.graph[previous] {
+					index := strings.IndexRune(neighbour, password[j])
+					if neighbour == "" || index < 0 {
+						continue
+					}
+
+					found = true
+					if index == 1 {
+						// The second character of a key is the shifted one
+						shiftedCount++
+					}
+					if lastDirection != direction {
+						turns++
+						lastDirection = direction
+					}
+					break
+				}
+			}
+
+			if found {
+				j++
+				continue
+			}
+
+			// Only walks of three or more keys are considered patterns
+			if j-i > 2 {
+				matches = append(matches, &Match{
+					Pattern:      SpatialPattern,
+					I:            i,
+					J:            j - 1,
+					Token:        string(password[i:j]),
+					Graph:        graph.name,
+					Turns:        turns,
+					ShiftedCount: shiftedCount,
+				})
+			}
+			i = j
+			break
+		}
+	}
+	return matches
+}
+
+func isShiftedKey(graph adjacencyGraph, r rune) bool {
+	for _, neighbours := range graph {
+		for _, neighbour := range neighbours {
+			if index := strings.IndexRune(neighbour, r); index == 1 {
+				return true
+			}
+		}
+	}
+	return false
+}
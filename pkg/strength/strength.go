@@ -0,0 +1,124 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package strength
+
+import (
+	"math"
+)
+
+// Score is a strength score from 0 (too guessable) to 4 (very unguessable).
+type Score int
+
+const (
+	// TooGuessable means the password is risky, it is guessed in fewer than 10^3 guesses.
+	TooGuessable Score = iota
+	// VeryGuessable means the password protects from throttled online attacks, fewer than 10^6 guesses.
+	VeryGuessable
+	// SomewhatGuessable means the password protects from unthrottled online attacks, fewer than 10^8 guesses.
+	SomewhatGuessable
+	// SafelyUnguessable means the password moderately protects from offline attacks, fewer than 10^10 guesses.
+	SafelyUnguessable
+	// VeryUnguessable means the password strongly protects from offline attacks, 10^10 guesses or more.
+	VeryUnguessable
+)
+
+// Result is the strength estimation of a password.
+type Result struct {
+	// Guesses is the estimated number of guesses needed to crack the password.
+	Guesses float64
+	// GuessesLog10 is the base-10 logarithm of Guesses.
+	GuessesLog10 float64
+	// Score is the strength score derived from the number of guesses.
+	Score Score
+	// Sequence is the least guessable sequence of matches that covers the password.
+	Sequence []*Match
+	// Feedback explains the score and how to improve the password.
+	Feedback Feedback
+}
+
+// Feedback is the feedback for a password.
+type Feedback struct {
+	// Warning explains what is wrong with the password, it is empty if there is nothing to warn about.
+	Warning string
+	// Suggestions are hints on how to improve the password.
+	Suggestions []string
+}
+
+var scoreThresholds = []float64{1e3, 1e6, 1e8, 1e10}
+
+// MaxLength is the number of characters of a password that are scored. The matching takes time
+// that grows quickly with the length, so longer passwords are truncated like zxcvbn does, which
+// can only underestimate their strength.
+const MaxLength = 100
+
+// Estimate estimates the strength of a password. User inputs, such as the user name or
+// e-mail address, are matched like dictionary words since they are easy to guess for an attacker.
+// Only the first MaxLength characters of the password are scored.
+func Estimate(password string, userInputs ...string) *Result {
+	passwordRunes := []rune(password)
+	if len(passwordRunes) > MaxLength {
+		passwordRunes = passwordRunes[:MaxLength]
+	}
+	matches := omnimatch(passwordRunes, newRankedDictionary(userInputs))
+	guesses, sequence := mostGuessableMatchSequence(passwordRunes, matches, false)
+	score := scoreOf(guesses)
+
+	return &Result{
+		Guesses:      guesses,
+		GuessesLog10: math.Log10(guesses),
+		Score:        score,
+		Sequence:     sequence,
+		Feedback:     getFeedback(score, sequence),
+	}
+}
+
+// String returns the name of the score.
+func (score Score) String() string {
+	switch score {
+	case TooGuessable:
+		return "too guessable"
+	case VeryGuessable:
+		return "very guessable"
+	case SomewhatGuessable:
+		return "somewhat guessable"
+	case SafelyUnguessable:
+		return "safely unguessable"
+	case VeryUnguessable:
+		return "very unguessable"
+	default:
+		return "unknown"
+	}
+}
+
+func scoreOf(guesses float64) Score {
+	// A small delta keeps passwords that are exactly at a threshold in the lower score
+	const delta = 5
+	for i, threshold := range scoreThresholds {
+		if guesses < threshold+delta {
+			return Score(i)
+		}
+	}
+	return VeryUnguessable
+}
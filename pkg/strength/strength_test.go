@@ -0,0 +1,142 @@
+package strength_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/strength"
+)
+
+func TestEstimateEmptyPasswordShouldSucceed(t *testing.T) {
+	result := strength.Estimate("")
+	assert.Equal(t, 1.0, result.Guesses)
+	assert.Equal(t, strength.TooGuessable, result.Score)
+	assert.Empty(t, result.Sequence)
+	assert.NotEmpty(t, result.Feedback.Suggestions)
+}
+
+func TestEstimateCommonPasswordShouldBeTooGuessable(t *testing.T) {
+	result := strength.Estimate("password")
+	assert.Equal(t, strength.TooGuessable, result.Score)
+	assert.Len(t, result.Sequence, 1)
+	assert.Equal(t, strength.DictionaryPattern, result.Sequence[0].Pattern)
+	assert.Equal(t, "passwords", result.Sequence[0].DictionaryName)
+	assert.Contains(t, result.Feedback.Warning, "top-10 common password")
+}
+
+func TestEstimateL33tPasswordShouldMatchDictionary(t *testing.T) {
+	result := strength.Estimate("P@ssw0rd")
+	assert.Equal(t, strength.TooGuessable, result.Score)
+	assert.Len(t, result.Sequence, 1)
+
+	match := result.Sequence[0]
+	assert.Equal(t, strength.DictionaryPattern, match.Pattern)
+	assert.Equal(t, "password", match.MatchedWord)
+	assert.True(t, match.L33t)
+	assert.Equal(t, 'a', match.Substitutions['@'])
+	assert.Equal(t, 'o', match.Substitutions['0'])
+}
+
+func TestEstimateReversedPasswordShouldMatchDictionary(t *testing.T) {
+	result := strength.Estimate("drowssap")
+	assert.Len(t, result.Sequence, 1)
+	assert.True(t, result.Sequence[0].Reversed)
+	assert.Equal(t, "password", result.Sequence[0].MatchedWord)
+}
+
+func TestEstimateKeyboardWalkShouldMatchSpatial(t *testing.T) {
+	result := strength.Estimate("zxcvfr")
+	assert.LessOrEqual(t, int(result.Score), int(strength.VeryGuessable))
+	assert.Len(t, result.Sequence, 1)
+
+	match := result.Sequence[0]
+	assert.Equal(t, strength.SpatialPattern, match.Pattern)
+	assert.Equal(t, "qwerty", match.Graph)
+	assert.Equal(t, 2, match.Turns)
+}
+
+func TestEstimateKeypadWalkShouldMatchSpatial(t *testing.T) {
+	result := strength.Estimate("7412589")
+	assert.Len(t, result.Sequence, 1)
+	assert.Equal(t, strength.SpatialPattern, result.Sequence[0].Pattern)
+	assert.Equal(t, "keypad", result.Sequence[0].Graph)
+}
+
+func TestEstimateRepeatShouldMatchRepeat(t *testing.T) {
+	result := strength.Estimate("xkcdxkcdxkcd")
+	assert.Len(t, result.Sequence, 1)
+
+	match := result.Sequence[0]
+	assert.Equal(t, strength.RepeatPattern, match.Pattern)
+	assert.Equal(t, "xkcd", match.BaseToken)
+	assert.Equal(t, 3, match.RepeatCount)
+}
+
+func TestEstimateSequenceShouldMatchSequence(t *testing.T) {
+	result := strength.Estimate("97531")
+	assert.Len(t, result.Sequence, 1)
+
+	match := result.Sequence[0]
+	assert.Equal(t, strength.SequencePattern, match.Pattern)
+	assert.Equal(t, "digits", match.SequenceName)
+	assert.False(t, match.Ascending)
+}
+
+func TestEstimateDateShouldMatchDate(t *testing.T) {
+	for _, password := range []string{"13/05/1990", "1990-05-13", "13051990"} {
+		result := strength.Estimate(password)
+		assert.Len(t, result.Sequence, 1, password)
+
+		match := result.Sequence[0]
+		assert.Equal(t, strength.DatePattern, match.Pattern, password)
+		assert.Equal(t, 1990, match.Year, password)
+		assert.Equal(t, 5, match.Month, password)
+		assert.Equal(t, 13, match.Day, password)
+	}
+}
+
+func TestEstimateUserInputsShouldMatchDictionary(t *testing.T) {
+	withoutInputs := strength.Estimate("mattiasedlund")
+	withInputs := strength.Estimate("mattiasedlund", "Mattias", "Edlund")
+	assert.Less(t, withInputs.Guesses, withoutInputs.Guesses)
+	assert.Equal(t, "user_inputs", withInputs.Sequence[0].DictionaryName)
+}
+
+func TestEstimateRandomPasswordShouldBeVeryUnguessable(t *testing.T) {
+	result := strength.Estimate("gIUfJ(5s%]Wd~=p/4H#X")
+	assert.Equal(t, strength.VeryUnguessable, result.Score)
+	assert.Empty(t, result.Feedback.Warning)
+	assert.Empty(t, result.Feedback.Suggestions)
+}
+
+func TestEstimatePassphraseShouldBeVeryUnguessable(t *testing.T) {
+	result := strength.Estimate("correct-horse-battery-staple")
+	assert.Equal(t, strength.VeryUnguessable, result.Score)
+}
+
+func TestEstimateSequenceShouldCoverPassword(t *testing.T) {
+	password := "Tr0ub4dor&3qwerty1990"
+	result := strength.Estimate(password)
+
+	position := 0
+	for _, match := range result.Sequence {
+		assert.Equal(t, position, match.I)
+		position = match.J + 1
+	}
+	assert.Equal(t, len(password), position)
+}
+
+func TestEstimateLongPasswordShouldBeTruncated(t *testing.T) {
+	password := strings.Repeat("gIUfJ(5s%]Wd~=p/4H#X", 500)
+	result := strength.Estimate(password)
+	assert.Equal(t, strength.VeryUnguessable, result.Score)
+
+	last := result.Sequence[len(result.Sequence)-1]
+	assert.Equal(t, strength.MaxLength-1, last.J)
+}
+
+func TestScoreStringShouldSucceed(t *testing.T) {
+	assert.Equal(t, "too guessable", strength.TooGuessable.String())
+	assert.Equal(t, "very unguessable", strength.VeryUnguessable.String())
+}
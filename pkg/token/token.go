@@ -0,0 +1,128 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package token generates and verifies API tokens in the style of GitHub, which are made up of a
+// prefix, a random base62 body and a base62 CRC32 checksum, such as acme_live_<body><checksum>.
+package token
+
+import (
+	"hash/crc32"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/rand"
+)
+
+const (
+	// DefaultBodyLength is the default length of the random body, which has about 178 bits of entropy.
+	DefaultBodyLength = 30
+	// ChecksumLength is the length of the base62 checksum, which fits any 32-bit value.
+	ChecksumLength = 6
+	// MinBodyLength is the minimum length of the random body, which has about 128 bits of entropy.
+	MinBodyLength = 22
+)
+
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// Config is the token generator configuration.
+type Config struct {
+	// Prefix identifies the tokens, such as acme_live_. It can only have ASCII letters, digits
+	// and underscores, and must end with an underscore, which separates it from the body.
+	Prefix     string
+	BodyLength int
+}
+
+// Generate generates a token.
+func Generate(config *Config) (string, error) {
+	if err := validatePrefix(config.Prefix); err != nil {
+		return "", err
+	}
+	if config.BodyLength < MinBodyLength {
+		return "", errors.Errorf("The body of a token cannot be shorter than %d characters", MinBodyLength)
+	}
+
+	body := make([]byte, config.BodyLength)
+	for i := range body {
+		index, err := rand.IntnE(len(base62Alphabet))
+		if err != nil {
+			return "", errors.Wrap(err, "Failed to generate a token")
+		}
+		body[i] = base62Alphabet[index]
+	}
+	return config.Prefix + string(body) + checksum(config.Prefix+string(body)), nil
+}
+
+// Verify verifies the format and checksum of a token. If prefix is not empty, the token must
+// also have that prefix.
+func Verify(token, prefix string) error {
+	separator := strings.LastIndex(token, "_")
+	if separator < 0 {
+		return errors.New("The token has no prefix")
+	}
+	tokenPrefix, rest := token[:separator+1], token[separator+1:]
+	if err := validatePrefix(tokenPrefix); err != nil {
+		return err
+	}
+	if prefix != "" && tokenPrefix != prefix {
+		return errors.Errorf("The token has the prefix %s instead of %s", tokenPrefix, prefix)
+	}
+
+	if len(rest) < MinBodyLength+ChecksumLength {
+		return errors.New("The token is too short")
+	}
+	for _, c := range rest {
+		if !strings.ContainsRune(base62Alphabet, c) {
+			return errors.Errorf("The token has an invalid character: %q", c)
+		}
+	}
+
+	body, sum := rest[:len(rest)-ChecksumLength], rest[len(rest)-ChecksumLength:]
+	if checksum(tokenPrefix+body) != sum {
+		return errors.New("The checksum of the token does not match")
+	}
+	return nil
+}
+
+// checksum returns the CRC32 of the prefix and body in base62, padded with zeros.
+func checksum(prefixAndBody string) string {
+	value := crc32.ChecksumIEEE([]byte(prefixAndBody))
+	encoded := make([]byte, ChecksumLength)
+	for i := len(encoded) - 1; i >= 0; i-- {
+		encoded[i] = base62Alphabet[value%62]
+		value /= 62
+	}
+	return string(encoded)
+}
+
+func validatePrefix(prefix string) error {
+	if prefix == "" || !strings.HasSuffix(prefix, "_") {
+		return errors.New("The prefix of a token must end with an underscore, such as acme_live_")
+	}
+	for _, c := range prefix {
+		if c != '_' && !strings.ContainsRune(base62Alphabet, c) {
+			return errors.Errorf("The prefix of a token has an invalid character: %q", c)
+		}
+	}
+	return nil
+}
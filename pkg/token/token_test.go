@@ -0,0 +1,60 @@
+package token_test
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/token"
+)
+
+func TestGenerateShouldSucceed(t *testing.T) {
+	config := &token.Config{Prefix: "acme_live_", BodyLength: token.DefaultBodyLength}
+	for i := 0; i < 50; i++ {
+		value, err := token.Generate(config)
+		assert.NoError(t, err)
+		assert.Regexp(t, regexp.MustCompile(`^acme_live_[0-9A-Za-z]{36}$`), value)
+		assert.NoError(t, token.Verify(value, ""))
+		assert.NoError(t, token.Verify(value, "acme_live_"))
+	}
+}
+
+func TestVerifyKnownTokenShouldSucceed(t *testing.T) {
+	// The CRC32 of "acme_test_0000000000000000000000" is 0x1042C9AA, which is 0ISgyw in base62
+	assert.NoError(t, token.Verify("acme_test_00000000000000000000000ISgyw", "acme_test_"))
+}
+
+func TestVerifyShouldFail(t *testing.T) {
+	value, _ := token.Generate(&token.Config{Prefix: "acme_live_", BodyLength: token.DefaultBodyLength})
+	tampered := []byte(value)
+	if tampered[12] == 'a' {
+		tampered[12] = 'b'
+	} else {
+		tampered[12] = 'a'
+	}
+
+	invalidTokens := []string{
+		string(tampered),
+		"acme_live_short",
+		"nounderscore0000000000000000000000000",
+		"acme-live_" + value[len("acme_live_"):],
+		value + "!",
+	}
+	for _, invalidToken := range invalidTokens {
+		assert.Error(t, token.Verify(invalidToken, ""), invalidToken)
+	}
+	assert.Error(t, token.Verify(value, "acme_test_"))
+}
+
+func TestGenerateShouldFail(t *testing.T) {
+	invalidConfigs := []token.Config{
+		{Prefix: "", BodyLength: 30},
+		{Prefix: "acme", BodyLength: 30},
+		{Prefix: "acme-live_", BodyLength: 30},
+		{Prefix: "acme_", BodyLength: 10},
+	}
+	for _, config := range invalidConfigs {
+		_, err := token.Generate(&config)
+		assert.Error(t, err, config.Prefix)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package verifier
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/bidi"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Credits: https://tools.ietf.org/html/rfc4013 and src/common/saslprep.c of PostgreSQL
+
+// nonASCIISpaces are the characters of table C.1.2 of RFC 3454, which are mapped to a space.
+var nonASCIISpaces = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a0, Hi: 0x00a0, Stride: 1},
+		{Lo: 0x1680, Hi: 0x1680, Stride: 1},
+		{Lo: 0x2000, Hi: 0x200b, Stride: 1},
+		{Lo: 0x202f, Hi: 0x202f, Stride: 1},
+		{Lo: 0x205f, Hi: 0x205f, Stride: 1},
+		{Lo: 0x3000, Hi: 0x3000, Stride: 1},
+	},
+}
+
+// mappedToNothing are the characters of table B.1 of RFC 3454, which are removed.
+var mappedToNothing = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00ad, Hi: 0x00ad, Stride: 1},
+		{Lo: 0x034f, Hi: 0x034f, Stride: 1},
+		{Lo: 0x1806, Hi: 0x1806, Stride: 1},
+		{Lo: 0x180b, Hi: 0x180d, Stride: 1},
+		{Lo: 0x200b, Hi: 0x200d, Stride: 1},
+		{Lo: 0x2060, Hi: 0x2060, Stride: 1},
+		{Lo: 0xfe00, Hi: 0xfe0f, Stride: 1},
+		{Lo: 0xfeff, Hi: 0xfeff, Stride: 1},
+	},
+}
+
+// prohibited are the characters of tables C.1.2 and C.2.1 to C.9 of RFC 3454. The noncharacters
+// of table C.4 at the end of each plane are checked separately.
+var prohibited = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x0000, Hi: 0x001f, Stride: 1},
+		{Lo: 0x007f, Hi: 0x00a0, Stride: 1},
+		{Lo: 0x0340, Hi: 0x0341, Stride: 1},
+		{Lo: 0x06dd, Hi: 0x06dd, Stride: 1},
+		{Lo: 0x070f, Hi: 0x070f, Stride: 1},
+		{Lo: 0x1680, Hi: 0x1680, Stride: 1},
+		{Lo: 0x180e, Hi: 0x180e, Stride: 1},
+		{Lo: 0x2000, Hi: 0x200f, Stride: 1},
+		{Lo: 0x2028, Hi: 0x202f, Stride: 1},
+		{Lo: 0x205f, Hi: 0x2063, Stride: 1},
+		{Lo: 0x206a, Hi: 0x206f, Stride: 1},
+		{Lo: 0x2ff0, Hi: 0x2ffb, Stride: 1},
+		{Lo: 0x3000, Hi: 0x3000, Stride: 1},
+		{Lo: 0xd800, Hi: 0xf8ff, Stride: 1},
+		{Lo: 0xfdd0, Hi: 0xfdef, Stride: 1},
+		{Lo: 0xfeff, Hi: 0xfeff, Stride: 1},
+		{Lo: 0xfff9, Hi: 0xfffd, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1d173, Hi: 0x1d17a, Stride: 1},
+		{Lo: 0xe0001, Hi: 0xe0001, Stride: 1},
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+		{Lo: 0xf0000, Hi: 0x10ffff, Stride: 1},
+	},
+}
+
+// saslprep prepares a password with SASLprep, or returns it unchanged if SASLprep rejects it,
+// which is what PostgreSQL does. Unassigned code points are those of the Unicode version of Go
+// rather than of Unicode 3.2, which only matters for characters that were added since.
+func saslprep(password string) string {
+	if !utf8.ValidString(password) {
+		return password
+	}
+	ascii := true
+	for i := 0; i < len(password); i++ {
+		if password[i] >= utf8.RuneSelf {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		// PostgreSQL uses ASCII passwords as they are, even with control characters
+		return password
+	}
+
+	mapped := make([]rune, 0, len(password))
+	for _, r := range password {
+		if unicode.Is(nonASCIISpaces, r) {
+			mapped = append(mapped, ' ')
+		} else if !unicode.Is(mappedToNothing, r) {
+			mapped = append(mapped, r)
+		}
+	}
+	prepared := norm.NFKC.String(string(mapped))
+
+	runes := []rune(prepared)
+	if len(runes) == 0 {
+		return password
+	}
+	hasRandAL, hasL := false, false
+	for _, r := range runes {
+		if unicode.Is(prohibited, r) || r&0xfffe == 0xfffe || !isAssigned(r) {
+			return password
+		}
+		properties, _ := bidi.LookupRune(r)
+		switch properties.Class() {
+		case bidi.R, bidi.AL:
+			hasRandAL = true
+		case bidi.L:
+			hasL = true
+		}
+	}
+	if hasRandAL {
+		// Strings with right-to-left characters cannot have left-to-right characters, and must
+		// start and end with a right-to-left character
+		first, _ := bidi.LookupRune(runes[0])
+		last, _ := bidi.LookupRune(runes[len(runes)-1])
+		if hasL || !isRandAL(first.Class()) || !isRandAL(last.Class()) {
+			return password
+		}
+	}
+	return prepared
+}
+
+func isAssigned(r rune) bool {
+	return unicode.In(r, unicode.L, unicode.M, unicode.N, unicode.P, unicode.S, unicode.Z, unicode.C)
+}
+
+func isRandAL(class bidi.Class) bool {
+	return class == bidi.R || class == bidi.AL
+}
@@ -0,0 +1,166 @@
+/*
+MIT License
+
+Copyright(c) 2019 Mattias Edlund
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+// Package verifier computes the server-side verifiers of passwords for database and directory
+// servers, so that the password itself never has to appear in SQL or LDIF.
+package verifier
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/whinarn/strongpass/pkg/crypt"
+	"github.com/whinarn/strongpass/pkg/rand"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Format is the format of a verifier.
+type Format string
+
+const (
+	// PostgresSCRAMSHA256 is the SCRAM-SHA-256 verifier of PostgreSQL, for CREATE ROLE ... PASSWORD.
+	PostgresSCRAMSHA256 Format = "scram-sha-256"
+	// MySQLCachingSHA2 is the caching_sha2_password verifier of MySQL, for
+	// CREATE USER ... IDENTIFIED WITH caching_sha2_password AS.
+	MySQLCachingSHA2 Format = "mysql-caching-sha2"
+	// LDAPSSHA is the salted SHA-1 {SSHA} value of the userPassword attribute of LDAP.
+	LDAPSSHA Format = "ssha"
+)
+
+const (
+	// SCRAMIterations is the number of iterations of the SCRAM-SHA-256 verifiers, which is the
+	// default of PostgreSQL.
+	SCRAMIterations = 4096
+	// CachingSHA2Rounds is the number of rounds of the caching_sha2_password verifiers, which is
+	// the default of MySQL.
+	CachingSHA2Rounds = 5000
+
+	scramSaltLength       = 16
+	cachingSHA2SaltLength = 20
+	sshaSaltLength        = 8
+)
+
+// Formats returns all the formats.
+func Formats() []Format {
+	return []Format{PostgresSCRAMSHA256, MySQLCachingSHA2, LDAPSSHA}
+}
+
+// ParseFormat parses the name of a format.
+func ParseFormat(name string) (Format, error) {
+	for _, format := range Formats() {
+		if name == string(format) {
+			return format, nil
+		}
+	}
+	return "", errors.Errorf("Unknown verifier format %q, expected scram-sha-256, mysql-caching-sha2 or ssha", name)
+}
+
+// Generate returns the verifier of a password with a random salt.
+func Generate(password string, format Format) (string, error) {
+	switch format {
+	case PostgresSCRAMSHA256:
+		salt := make([]byte, scramSaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", errors.Wrap(err, "Failed to generate a salt")
+		}
+		return SCRAMSHA256(password, salt, SCRAMIterations), nil
+	case MySQLCachingSHA2:
+		// Any salt without NUL or $ is valid, but printable characters keep the verifier safe to paste into SQL
+		salt, err := generatePrintableSalt(cachingSHA2SaltLength)
+		if err != nil {
+			return "", err
+		}
+		return CachingSHA2(password, salt)
+	case LDAPSSHA:
+		salt := make([]byte, sshaSaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return "", errors.Wrap(err, "Failed to generate a salt")
+		}
+		return SSHA(password, salt), nil
+	default:
+		return "", errors.Errorf("Unknown verifier format %q", format)
+	}
+}
+
+// SCRAMSHA256 returns the SCRAM-SHA-256 verifier of a password in the format of PostgreSQL,
+// SCRAM-SHA-256$<iterations>:<salt>$<StoredKey>:<ServerKey>. The password is prepared with
+// SASLprep first, like PostgreSQL does.
+func SCRAMSHA256(password string, salt []byte, iterations int) string {
+	saltedPassword := pbkdf2.Key([]byte(saslprep(password)), salt, iterations, sha256.Size, sha256.New)
+	clientKey := hmacSHA256(saltedPassword, "Client Key")
+	storedKey := sha256.Sum256(clientKey)
+	serverKey := hmacSHA256(saltedPassword, "Server Key")
+	return fmt.Sprintf("SCRAM-SHA-256$%d:%s$%s:%s", iterations,
+		base64.StdEncoding.EncodeToString(salt),
+		base64.StdEncoding.EncodeToString(storedKey[:]),
+		base64.StdEncoding.EncodeToString(serverKey))
+}
+
+// CachingSHA2 returns the caching_sha2_password verifier of a password in the format
+// of MySQL, $A$005$<salt><digest>, where the digest is a SHA-256 crypt digest. The salt must be 20
+// bytes without NUL or $ characters.
+func CachingSHA2(password string, salt []byte) (string, error) {
+	if len(salt) != cachingSHA2SaltLength {
+		return "", errors.Errorf("The salt of a caching_sha2_password verifier must be %d bytes", cachingSHA2SaltLength)
+	}
+	for _, b := range salt {
+		if b == 0 || b == '$' {
+			return "", errors.New("The salt of a caching_sha2_password verifier cannot have NUL or $ characters")
+		}
+	}
+	return fmt.Sprintf("$A$%03X$%s%s", CachingSHA2Rounds/1000, salt,
+		crypt.SHA256CryptDigest(password, salt, CachingSHA2Rounds)), nil
+}
+
+// SSHA returns the {SSHA} value of a password, which is the SHA-1 of the password and salt
+// followed by the salt, in base64.
+func SSHA(password string, salt []byte) string {
+	h := sha1.New()
+	h.Write([]byte(password))
+	h.Write(salt)
+	return "{SSHA}" + base64.StdEncoding.EncodeToString(append(h.Sum(nil), salt...))
+}
+
+func hmacSHA256(key []byte, message string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(message))
+	return h.Sum(nil)
+}
+
+func generatePrintableSalt(length int) ([]byte, error) {
+	const alphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	salt := make([]byte, length)
+	for i := range salt {
+		index, err := rand.IntnE(len(alphabet))
+		if err != nil {
+			return nil, errors.Wrap(err, "Failed to generate a salt")
+		}
+		salt[i] = alphabet[index]
+	}
+	return salt, nil
+}
@@ -0,0 +1,74 @@
+package verifier_test
+
+import (
+	"encoding/base64"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/whinarn/strongpass/pkg/verifier"
+)
+
+func TestSCRAMSHA256ShouldSucceed(t *testing.T) {
+	// The salt of the example of RFC 7677
+	salt, _ := base64.StdEncoding.DecodeString("W22ZaJ0SNY7soEsUEjb6gQ==")
+	tests := []struct {
+		password string
+		expected string
+	}{
+		{"pencil", "SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$WG5d8oPm3OtcPnkdi4Uo7BkeZkBFzpcXkuLmtbsT4qY=:wfPLwcE6nTWhTAmQ7tl2KeoiWGPlZqQxSrmfPwDl2dU="},
+		// SASLprep maps the no-break space to a space, removes the soft hyphen and decomposes ½ into 1⁄2
+		{"p½ss\u00a0wörd\u00ad", "SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$SiaGbX6jij12CYvuh+LWzLGheGXCraCRZLo41LeqD6g=:Lf+hia8CsjvuZmfOOTOTgoyN3foF47RVZstkl7ha604="},
+		// Passwords that SASLprep rejects, such as with private use or mixed-direction characters, are used as they are
+		{"pass\ue000word", "SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$DlAqRp6u3jXNDOsxxQehQMNlZs3RbBmJ4lozrHBEQKk=:ZhK2luTS6nUeSUO9oKgHqzNbKzIRxLo37h2uUZZzO+c="},
+		{"ا1ب", "SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$i4jjeZTz9e9hDQnMhqsE64of93nIaC3xMnI4cV9m+WQ=:+K25MahimsteuXSNs7JH91qzHtXjZk6IJke6PnIjOqY="},
+		{"اabc", "SCRAM-SHA-256$4096:W22ZaJ0SNY7soEsUEjb6gQ==$P8aG95Ph6l2lcTwVXj/zzpyV7ahkbkXaU2RKvXDbT2E=:c0qG2Qay4gAWT1vO0rAmAg0p+ARpHbt8CqyB/seoXnQ="},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, verifier.SCRAMSHA256(test.password, salt, verifier.SCRAMIterations), test.password)
+	}
+}
+
+func TestCachingSHA2ShouldSucceed(t *testing.T) {
+	value, err := verifier.CachingSHA2("secret password", []byte("0123456789abcdefghij"))
+	assert.NoError(t, err)
+	assert.Equal(t, "$A$005$0123456789abcdefghijZXTLrPpgeD3LK.u9xBu03KLvF6OoEuNUVNMI67HHAcC", value)
+}
+
+func TestCachingSHA2ShouldFail(t *testing.T) {
+	_, err := verifier.CachingSHA2("secret password", []byte("too short"))
+	assert.Error(t, err)
+	_, err = verifier.CachingSHA2("secret password", []byte("0123456789abcdefghi$"))
+	assert.Error(t, err)
+}
+
+func TestSSHAShouldSucceed(t *testing.T) {
+	value := verifier.SSHA("secret", []byte{1, 2, 3, 4, 5, 6, 7, 8})
+	assert.Equal(t, "{SSHA}lHFzXul4wnzRItssVcTnvXWRjNgBAgMEBQYHCA==", value)
+}
+
+func TestGenerateShouldSucceed(t *testing.T) {
+	patterns := map[verifier.Format]*regexp.Regexp{
+		verifier.PostgresSCRAMSHA256: regexp.MustCompile(`^SCRAM-SHA-256\$4096:[A-Za-z0-9+/]{22}==\$[A-Za-z0-9+/]{43}=:[A-Za-z0-9+/]{43}=$`),
+		verifier.MySQLCachingSHA2:    regexp.MustCompile(`^\$A\$005\$[./0-9A-Za-z]{63}$`),
+		verifier.LDAPSSHA:            regexp.MustCompile(`^\{SSHA\}[A-Za-z0-9+/]{38}==$`),
+	}
+	for _, format := range verifier.Formats() {
+		value, err := verifier.Generate("correct horse battery staple", format)
+		assert.NoError(t, err, format)
+		assert.Regexp(t, patterns[format], value)
+
+		other, err := verifier.Generate("correct horse battery staple", format)
+		assert.NoError(t, err, format)
+		assert.NotEqual(t, value, other, format)
+	}
+}
+
+func TestParseFormatShouldFail(t *testing.T) {
+	_, err := verifier.ParseFormat("md5")
+	assert.Error(t, err)
+	format, err := verifier.ParseFormat("ssha")
+	assert.NoError(t, err)
+	assert.Equal(t, verifier.LDAPSSHA, format)
+}